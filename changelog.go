@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+)
+
+// upgradeSteps turns the normal plan into an update plan: installed
+// GitHub-release components that are behind upstream are flipped to
+// "install", after showing the release notes between the two versions
+// and confirming — unless yes is set. Components without release
+// metadata keep their planned action.
+func upgradeSteps(cmds []installer.InstallCommand, yes bool) []installer.Step {
+	steps := installer.Plan(cmds)
+	for i, step := range steps {
+		c := step.Command
+		if step.Action != "skip" || c.GitHubRepo == "" || c.LatestVersion == nil {
+			continue
+		}
+		have := versionToken(c.Version())
+		latest, err := c.LatestVersion()
+		if err != nil {
+			logger.Debug("%s: latest version: %v", c.Name, err)
+			continue
+		}
+		want := versionToken(latest)
+		if have == "" || want == "" || have == want {
+			continue
+		}
+		logger.Info("%s: %s -> %s", c.Name, have, want)
+		if notes := releaseNotesBetween(c.GitHubRepo, have); notes != "" {
+			fmt.Println(notes)
+		}
+		if !yes && !confirm(fmt.Sprintf("Upgrade %s to %s?", c.Name, want)) {
+			logger.Skip("%s stays at %s", c.Name, have)
+			continue
+		}
+		steps[i].Action = "install"
+	}
+	return steps
+}
+
+// releaseNotesBetween fetches the release notes newer than the
+// installed version, newest first, from the GitHub releases API.
+// Failures return an empty string: missing notes must not block an
+// update.
+func releaseNotesBetween(repo, installed string) string {
+	resp, err := installer.HTTPClient.Get("https://api.github.com/repos/" + repo + "/releases?per_page=20")
+	if err != nil {
+		logger.Debug("release notes for %s: %v", repo, err)
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		logger.Debug("release notes for %s: %s", repo, resp.Status)
+		return ""
+	}
+	var releases []struct {
+		TagName    string `json:"tag_name"`
+		Name       string `json:"name"`
+		Body       string `json:"body"`
+		Prerelease bool   `json:"prerelease"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, r := range releases {
+		tag := versionToken(r.TagName)
+		if r.Prerelease || tag == "" || compareVersions(tag, installed) <= 0 {
+			continue
+		}
+		title := r.Name
+		if title == "" {
+			title = r.TagName
+		}
+		fmt.Fprintf(&b, "\n--- %s ---\n%s\n", title, strings.TrimSpace(r.Body))
+	}
+	return b.String()
+}
+
+// compareVersions compares dotted numeric versions: -1, 0, or 1.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var an, bn int
+		if i < len(as) {
+			an, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			bn, _ = strconv.Atoi(bs[i])
+		}
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		}
+	}
+	return 0
+}
+
+// confirm asks a yes/no question on the terminal; without one the
+// answer is no, so unattended updates never upgrade silently.
+func confirm(question string) bool {
+	if !logger.IsTerminal(os.Stdin) {
+		logger.Info("%s — no terminal, skipping (use -yes to upgrade unattended)", question)
+		return false
+	}
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	a := strings.ToLower(strings.TrimSpace(answer))
+	return a == "y" || a == "yes"
+}
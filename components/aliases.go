@@ -0,0 +1,81 @@
+package components
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/installer/fileedit"
+	"github.com/ishida722/setup/resources"
+)
+
+// Alias is one declared shorthand, rendered as a fish abbreviation and
+// a bash/zsh alias so every shell stays consistent.
+type Alias struct {
+	Name, Command string
+}
+
+// ParseAliases parses comma-separated name=command pairs, e.g.
+// "v=nvim,g=git".
+func ParseAliases(spec string) ([]Alias, error) {
+	var aliases []Alias
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, command, ok := strings.Cut(pair, "=")
+		if !ok || name == "" || command == "" {
+			return nil, fmt.Errorf("alias %q: want name=command", pair)
+		}
+		aliases = append(aliases, Alias{Name: name, Command: command})
+	}
+	return aliases, nil
+}
+
+// aliasBlockMarker names the managed block in .bashrc/.zshrc;
+// everything between its markers is owned by setup and rewritten on
+// every change.
+const aliasBlockMarker = "setup-managed aliases"
+
+// ShellAliases renders the aliases into a fish conf.d file (the whole
+// file is managed) and into a managed block in .bashrc and, when it
+// exists, .zshrc. Removing an alias from the declaration removes it
+// from all shells on the next run.
+func ShellAliases(aliases []Alias) installer.InstallCommand {
+	var fish, sh strings.Builder
+	for _, a := range aliases {
+		fmt.Fprintf(&fish, "abbr -a %s %s\n", a.Name, shellQuote(a.Command))
+		fmt.Fprintf(&sh, "alias %s=%s\n", a.Name, shellQuote(a.Command))
+	}
+	cmd := installer.InstallCommand{
+		Name: "shell aliases",
+		InstallFunc: func() error {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return err
+			}
+			for _, rc := range []string{".bashrc", ".zshrc"} {
+				path := filepath.Join(home, rc)
+				if rc == ".zshrc" {
+					if _, err := os.Stat(path); err != nil {
+						continue
+					}
+				}
+				if _, err := fileedit.EnsureBlock(path, aliasBlockMarker, sh.String()); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		cmd.Resources = []installer.Resource{resources.FileContent{
+			Path:    filepath.Join(home, ".config/fish/conf.d/setup_abbr.fish"),
+			Content: []byte(fish.String()),
+		}}
+	}
+	return cmd
+}
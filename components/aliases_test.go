@@ -0,0 +1,47 @@
+package components
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ishida722/setup/installer/fileedit"
+)
+
+func TestParseAliases(t *testing.T) {
+	aliases, err := ParseAliases("v=nvim, g=git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(aliases) != 2 || aliases[0].Name != "v" || aliases[1].Command != "git" {
+		t.Fatalf("parsed wrong: %+v", aliases)
+	}
+	if _, err := ParseAliases("broken"); err == nil {
+		t.Fatal("entry without = accepted")
+	}
+}
+
+func TestAliasBlockIsIdempotent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".bashrc")
+	if err := os.WriteFile(path, []byte("export EDITOR=nvim\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fileedit.EnsureBlock(path, aliasBlockMarker, "alias v='nvim'\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fileedit.EnsureBlock(path, aliasBlockMarker, "alias g='git'\n"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	if !strings.HasPrefix(got, "export EDITOR=nvim\n") {
+		t.Fatalf("user content lost:\n%s", got)
+	}
+	if strings.Contains(got, "alias v=") || strings.Count(got, "# BEGIN "+aliasBlockMarker) != 1 {
+		t.Fatalf("old block not replaced:\n%s", got)
+	}
+}
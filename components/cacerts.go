@@ -0,0 +1,49 @@
+package components
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// systemCABundle is where update-ca-certificates writes the combined
+// trust store; node, npm, and git are pointed at it so every HTTPS
+// client agrees behind a TLS-inspecting proxy.
+const systemCABundle = "/etc/ssl/certs/ca-certificates.crt"
+
+// CACertificates installs custom CA certificates (PEM files) into the
+// system trust store and configures node, npm, and git to use it.
+// Without this, every HTTPS step fails behind a TLS-inspecting proxy,
+// so it runs before anything that downloads.
+func CACertificates(certs []string) installer.InstallCommand {
+	var checks []installer.Check
+	var cmds []string
+	for _, cert := range certs {
+		dest := "/usr/local/share/ca-certificates/" + caCertName(cert)
+		checks = append(checks, installer.PathExists(dest))
+		cmds = append(cmds, fmt.Sprintf("sudo cp %q %q", cert, dest))
+	}
+	cmds = append(cmds,
+		"sudo update-ca-certificates",
+		// node reads NODE_EXTRA_CA_CERTS at startup; /etc/environment
+		// reaches every session.
+		fmt.Sprintf(`grep -q NODE_EXTRA_CA_CERTS /etc/environment || echo 'NODE_EXTRA_CA_CERTS=%s' | sudo tee -a /etc/environment > /dev/null`, systemCABundle),
+		fmt.Sprintf("npm config set cafile %s --location=user || true", systemCABundle),
+		fmt.Sprintf("git config --global http.sslCAInfo %s", systemCABundle),
+	)
+	return installer.InstallCommand{
+		Name:            "corporate CA certificates",
+		Checks:          checks,
+		InstallCommands: cmds,
+	}
+}
+
+// caCertName derives the .crt file name update-ca-certificates
+// expects from the source path.
+func caCertName(path string) string {
+	base := filepath.Base(path)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	return base + ".crt"
+}
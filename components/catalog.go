@@ -0,0 +1,287 @@
+// Package components holds the catalog of everything setup can
+// install, ported from playbook.yml.
+package components
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/resources"
+)
+
+// CatalogEntry names a built-in component without building it, so
+// listing and completion stay instant as the catalog grows; Build
+// runs the constructor (architecture detection, URL assembly) only
+// when the component is actually selected.
+type CatalogEntry struct {
+	Name  string
+	Build func() installer.InstallCommand
+}
+
+// CatalogEntries returns the built-in components in install order.
+func CatalogEntries() []CatalogEntry {
+	return []CatalogEntry{
+		{"base dependencies", BaseDependencies},
+		{"Fish shell (default shell)", FishShell},
+		{"configuration repositories", func() installer.InstallCommand { return Dotfiles(nil) }},
+		{"Node.js", NodeJS},
+		{"Claude Code", ClaudeCode},
+		{"Neovim", Neovim},
+		{"Yazi", Yazi},
+		{"Lazygit", Lazygit},
+		{"GitHub CLI", GitHubCLI},
+	}
+}
+
+// Catalog builds the default set of components in install order.
+func Catalog() []installer.InstallCommand {
+	entries := CatalogEntries()
+	cmds := make([]installer.InstallCommand, 0, len(entries))
+	for _, e := range entries {
+		cmds = append(cmds, e.Build())
+	}
+	return cmds
+}
+
+// BaseDependencies installs the system packages everything else
+// relies on, through whichever package manager the platform has.
+func BaseDependencies() installer.InstallCommand {
+	var cmds []string
+	if update := installer.PkgUpdate(); update != "" {
+		cmds = append(cmds, update)
+	}
+	cmds = append(cmds, installer.PkgInstall("curl", "git", "file", "fish"))
+	return installer.InstallCommand{
+		Name:            "base dependencies",
+		License:         "various",
+		Domains:         []string{"archive.ubuntu.com"},
+		InstallCommands: cmds,
+	}
+}
+
+// FishShell makes Fish the login shell (effective after re-login).
+func FishShell() installer.InstallCommand {
+	return installer.InstallCommand{
+		Name:              "Fish shell (default shell)",
+		License:           "GPL-2.0-only",
+		Resources:         []installer.Resource{resources.LoginShell{Shell: "/usr/bin/fish"}},
+		PostCheckCommands: []string{"fish -c exit"},
+	}
+}
+
+// cloneConcurrency bounds parallel clones.
+const cloneConcurrency = 3
+
+// ShallowClones makes cloneRepos fetch only the latest commit with no
+// blobs from other revisions (--depth=1 --filter=blob:none), for fast
+// provisioning on slow links. UnshallowConfigRepos converts such
+// clones to full ones later.
+var ShallowClones bool
+
+// UnshallowConfigRepos converts shallow config repo clones into full
+// ones, for when the user wants history after a -shallow-config
+// provisioning. Repos that are already full are left alone.
+func UnshallowConfigRepos() installer.InstallCommand {
+	return installer.InstallCommand{
+		Name:    "full history for configuration repositories",
+		Domains: []string{"github.com"},
+		InstallFunc: func() error {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return err
+			}
+			for _, d := range DefaultDotfiles {
+				dest := d.cloneDir(home)
+				if _, err := os.Stat(filepath.Join(dest, ".git", "shallow")); err != nil {
+					continue
+				}
+				if err := installer.Run(fmt.Sprintf("git -C %q fetch --unshallow", dest)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// NodeJS installs Node.js from NodeSource: the LTS release by
+// default, or the current release when the machine follows the
+// "current" channel.
+func NodeJS() installer.InstallCommand {
+	script := "https://deb.nodesource.com/setup_lts.x"
+	if channelFor("Node.js", "lts") == "current" {
+		script = "https://deb.nodesource.com/setup_current.x"
+	}
+	return installer.InstallCommand{
+		Name:              "Node.js",
+		License:           "MIT",
+		Domains:           []string{"deb.nodesource.com"},
+		VersionCommand:    "node --version",
+		VersionFromOutput: `Setting up nodejs \(([0-9][^)]*)\)`,
+		LatestVersion:     aptCandidate("nodejs"),
+		PostCheckCommands: []string{`node -e ""`},
+		Checks:            []installer.Check{installer.BinaryExists("node")},
+		VerifiedScripts: []installer.VerifiedScript{
+			{URL: script, Sudo: true},
+		},
+		InstallCommands: []string{
+			"sudo apt-get install -y nodejs",
+		},
+	}
+}
+
+// ClaudeCode installs Claude Code globally via npm.
+func ClaudeCode() installer.InstallCommand {
+	return installer.InstallCommand{
+		Name:           "Claude Code",
+		License:        "Proprietary",
+		DependsOn:      []string{"Node.js"},
+		Domains:        []string{"registry.npmjs.org"},
+		VersionCommand: "claude --version",
+		LatestVersion:  npmLatest("@anthropic-ai/claude-code"),
+		Checks:         []installer.Check{installer.BinaryExists("claude")},
+		InstallCommands: []string{
+			"sudo npm install -g @anthropic-ai/claude-code",
+		},
+	}
+}
+
+// Neovim installs the latest release binary; apt is bypassed because
+// its packaged version is too old. The release asset matches the
+// machine architecture, and on the nightly channel the prerelease
+// build is installed instead of the latest stable tag.
+func Neovim() installer.InstallCommand {
+	asset := neovimAsset()
+	if asset == "" {
+		return installer.InstallCommand{
+			Name:            "Neovim",
+			InstallCommands: []string{fmt.Sprintf(`echo "unsupported architecture: %s" >&2; exit 1`, runtime.GOARCH)},
+		}
+	}
+	url := fmt.Sprintf("https://github.com/neovim/neovim/releases/latest/download/%s.tar.gz", asset)
+	latest := githubLatestTag("neovim/neovim")
+	if channelFor("Neovim", "stable") == "nightly" {
+		url = fmt.Sprintf("https://github.com/neovim/neovim/releases/download/nightly/%s.tar.gz", asset)
+		latest = githubLatestPrerelease("neovim/neovim")
+	}
+	tarball := NeovimTarball()
+	return installer.InstallCommand{
+		Name:              "Neovim",
+		License:           "Apache-2.0",
+		Domains:           []string{"github.com", "objects.githubusercontent.com"},
+		VersionCommand:    "nvim --version",
+		LatestVersion:     latest,
+		GitHubRepo:        "neovim/neovim",
+		PostCheckCommands: []string{"nvim --headless +q"},
+		Binaries:          []string{"/usr/local/bin/nvim"},
+		Checks:            []installer.Check{installer.BinaryExists("nvim")},
+		Downloads: []installer.Download{
+			{URL: url, Dest: tarball},
+		},
+		InstallCommands: []string{
+			fmt.Sprintf("test -s %s || curl -fsSL -o %s %s", tarball, tarball, url),
+			"sudo tar -C /opt -xzf " + tarball,
+			fmt.Sprintf("sudo ln -sf /opt/%s/bin/nvim /usr/local/bin/nvim", asset),
+		},
+	}
+}
+
+// neovimAsset names the Neovim release asset for the machine
+// architecture, or "" when unsupported.
+func neovimAsset() string {
+	switch runtime.GOARCH {
+	case "amd64":
+		return "nvim-linux-x86_64"
+	case "arm64":
+		return "nvim-linux-arm64"
+	}
+	return ""
+}
+
+// NeovimTarball is where the Neovim release tarball is downloaded, or
+// "" on unsupported architectures. Exported so gc collects the same
+// path the installer writes.
+func NeovimTarball() string {
+	asset := neovimAsset()
+	if asset == "" {
+		return ""
+	}
+	return fmt.Sprintf("/tmp/%s.tar.gz", asset)
+}
+
+// Yazi installs the release binary matching the machine architecture.
+func Yazi() installer.InstallCommand {
+	var target string
+	switch runtime.GOARCH {
+	case "amd64":
+		target = "x86_64-unknown-linux-gnu"
+	case "arm64":
+		target = "aarch64-unknown-linux-gnu"
+	default:
+		return installer.InstallCommand{
+			Name:            "Yazi",
+			InstallCommands: []string{fmt.Sprintf(`echo "unsupported architecture: %s" >&2; exit 1`, runtime.GOARCH)},
+		}
+	}
+	url := fmt.Sprintf("https://github.com/sxyazi/yazi/releases/latest/download/yazi-%s.zip", target)
+	return installer.InstallCommand{
+		Name:           "Yazi",
+		License:        "MIT",
+		Domains:        []string{"github.com", "objects.githubusercontent.com"},
+		VersionCommand: "yazi --version",
+		LatestVersion:  githubLatestTag("sxyazi/yazi"),
+		GitHubRepo:     "sxyazi/yazi",
+		Binaries:       []string{"/usr/local/bin/yazi"},
+		Checks:         []installer.Check{installer.BinaryExists("yazi")},
+		Downloads: []installer.Download{
+			{URL: url, Dest: "/tmp/yazi.zip"},
+		},
+		InstallCommands: []string{
+			fmt.Sprintf("test -s /tmp/yazi.zip || curl -fsSL -o /tmp/yazi.zip %q", url),
+			"sudo unzip -o /tmp/yazi.zip -d /opt",
+			fmt.Sprintf("sudo ln -sf /opt/yazi-%s/yazi /usr/local/bin/yazi", target),
+		},
+	}
+}
+
+// Lazygit installs the latest release binary.
+func Lazygit() installer.InstallCommand {
+	return installer.InstallCommand{
+		Name:           "Lazygit",
+		License:        "MIT",
+		Domains:        []string{"api.github.com", "github.com", "objects.githubusercontent.com"},
+		VersionCommand: "lazygit --version",
+		LatestVersion:  githubLatestTag("jesseduffield/lazygit"),
+		GitHubRepo:     "jesseduffield/lazygit",
+		Binaries:       []string{"/usr/local/bin/lazygit"},
+		Checks:         []installer.Check{installer.BinaryExists("lazygit")},
+		Scripts: []installer.Script{
+			{Name: "install_lazygit.sh", Source: installLazygitScript},
+		},
+	}
+}
+
+//go:embed scripts/install_lazygit.sh
+var installLazygitScript []byte
+
+// GitHubCLI installs gh from the official apt repository.
+func GitHubCLI() installer.InstallCommand {
+	return installer.InstallCommand{
+		Name:           "GitHub CLI",
+		License:        "MIT",
+		Domains:        []string{"cli.github.com"},
+		VersionCommand: "gh --version",
+		LatestVersion:  aptCandidate("gh"),
+		Checks:         []installer.Check{installer.BinaryExists("gh")},
+		InstallCommands: []string{
+			"curl -fsSL https://cli.github.com/packages/githubcli-archive-keyring.gpg | sudo dd of=/usr/share/keyrings/githubcli-archive-keyring.gpg",
+			`echo "deb [arch=$(dpkg --print-architecture) signed-by=/usr/share/keyrings/githubcli-archive-keyring.gpg] https://cli.github.com/packages stable main" | sudo tee /etc/apt/sources.list.d/github-cli.list > /dev/null`,
+			"sudo apt-get update",
+			"sudo apt-get install -y gh",
+		},
+	}
+}
@@ -0,0 +1,59 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+)
+
+// supportedChannels lists which release channels each channel-aware
+// component can follow. Everything else is implicitly stable-only.
+var supportedChannels = map[string][]string{
+	"Neovim":  {"stable", "nightly"},
+	"Node.js": {"lts", "current"},
+}
+
+// Channels is the per-component release channel for this run. main
+// loads it from state (each machine remembers what it follows) and
+// applies any -channel override before the catalog is built.
+var Channels = map[string]string{}
+
+// channelFor returns the channel the component follows, defaulting to
+// its stable-equivalent channel.
+func channelFor(name, fallback string) string {
+	if c, ok := Channels[name]; ok && c != "" {
+		return c
+	}
+	return fallback
+}
+
+// ParseChannels parses comma-separated name=channel pairs, e.g.
+// "Neovim=nightly,Node.js=current", validating each against the
+// channels the component actually publishes.
+func ParseChannels(spec string) (map[string]string, error) {
+	out := map[string]string{}
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, channel, ok := strings.Cut(pair, "=")
+		if !ok || name == "" || channel == "" {
+			return nil, fmt.Errorf("channel %q: want component=channel", pair)
+		}
+		valid, ok := supportedChannels[name]
+		if !ok {
+			return nil, fmt.Errorf("%s has no release channels", name)
+		}
+		found := false
+		for _, v := range valid {
+			if v == channel {
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s: unknown channel %q (supported: %s)", name, channel, strings.Join(valid, ", "))
+		}
+		out[name] = channel
+	}
+	return out, nil
+}
@@ -0,0 +1,36 @@
+package components
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseChannels(t *testing.T) {
+	got, err := ParseChannels("Neovim=nightly,Node.js=current")
+	if err != nil || got["Neovim"] != "nightly" || got["Node.js"] != "current" {
+		t.Fatalf("ParseChannels: %v, %v", got, err)
+	}
+	if _, err := ParseChannels("Neovim=beta"); err == nil {
+		t.Fatal("unknown channel accepted")
+	}
+	if _, err := ParseChannels("Fish=nightly"); err == nil {
+		t.Fatal("channel-less component accepted")
+	}
+}
+
+func TestChannelsResolveInCatalog(t *testing.T) {
+	old := Channels
+	defer func() { Channels = old }()
+
+	Channels = map[string]string{}
+	if url := Neovim().Downloads[0].URL; !strings.Contains(url, "releases/latest") {
+		t.Fatalf("stable Neovim downloads %s", url)
+	}
+	Channels = map[string]string{"Neovim": "nightly", "Node.js": "current"}
+	if url := Neovim().Downloads[0].URL; !strings.Contains(url, "download/nightly/") {
+		t.Fatalf("nightly Neovim downloads %s", url)
+	}
+	if url := NodeJS().VerifiedScripts[0].URL; !strings.Contains(url, "setup_current.x") {
+		t.Fatalf("current Node.js bootstraps from %s", url)
+	}
+}
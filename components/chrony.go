@@ -0,0 +1,46 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// Chrony installs chrony and makes sure the service is active, so
+// clock drift does not break TLS handshakes or build caches. When
+// servers are given they replace the distro pool via a sources.d
+// drop-in.
+func Chrony(servers []string) installer.InstallCommand {
+	checks := []string{
+		"dpkg -s chrony > /dev/null 2>&1",
+		"systemctl is-active --quiet chrony",
+	}
+	commands := []string{
+		"sudo apt-get install -y chrony",
+	}
+	if len(servers) > 0 {
+		var lines []string
+		for _, s := range servers {
+			lines = append(lines, fmt.Sprintf("server %s iburst", s))
+		}
+		conf := strings.Join(lines, "\n") + "\n"
+		checks = append(checks, fmt.Sprintf("cmp -s /etc/chrony/sources.d/setup.sources <(printf '%%s' %s)", shellQuote(conf)))
+		commands = append(commands,
+			fmt.Sprintf("printf '%%s' %s | sudo tee /etc/chrony/sources.d/setup.sources > /dev/null", shellQuote(conf)),
+			"sudo systemctl restart chrony",
+		)
+	}
+	commands = append(commands, "sudo systemctl enable --now chrony")
+	return installer.InstallCommand{
+		Name:            "chrony (time sync)",
+		CheckCommands:   checks,
+		InstallCommands: commands,
+	}
+}
+
+// shellQuote wraps s in single quotes for safe interpolation into a
+// shell command.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,70 @@
+package components
+
+import (
+	"os"
+	"strings"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// clipboardEnvironment detects what the clipboard has to talk to:
+// "wsl", "wayland", "x11", or "headless". WSL is checked first because
+// WSLg sets WAYLAND_DISPLAY while the clipboard still belongs to
+// Windows.
+func clipboardEnvironment() string {
+	if version, err := os.ReadFile("/proc/version"); err == nil &&
+		strings.Contains(strings.ToLower(string(version)), "microsoft") {
+		return "wsl"
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		return "wayland"
+	}
+	if os.Getenv("DISPLAY") != "" {
+		return "x11"
+	}
+	return "headless"
+}
+
+// win32yankURL pins the win32yank release used under WSL.
+const win32yankURL = "https://github.com/equalsraf/win32yank/releases/download/v0.1.1/win32yank-x64.zip"
+
+// Clipboard installs the clipboard tool the current display server
+// needs — wl-clipboard on Wayland, xclip on X11, win32yank under WSL —
+// so editor clipboard integration works out of the box. On headless
+// machines it is a no-op.
+func Clipboard() installer.InstallCommand {
+	switch clipboardEnvironment() {
+	case "wsl":
+		return installer.InstallCommand{
+			Name:     "clipboard tool (win32yank for WSL)",
+			Domains:  []string{"github.com", "objects.githubusercontent.com"},
+			Binaries: []string{"/usr/local/bin/win32yank.exe"},
+			Checks:   []installer.Check{installer.BinaryExists("win32yank.exe")},
+			Downloads: []installer.Download{
+				{URL: win32yankURL, Dest: "/tmp/win32yank.zip"},
+			},
+			InstallCommands: []string{
+				"test -s /tmp/win32yank.zip || curl -fsSL -o /tmp/win32yank.zip " + win32yankURL,
+				"unzip -o /tmp/win32yank.zip win32yank.exe -d /tmp",
+				"sudo install -m 755 /tmp/win32yank.exe /usr/local/bin/win32yank.exe",
+			},
+		}
+	case "wayland":
+		return installer.InstallCommand{
+			Name:            "clipboard tool (wl-clipboard)",
+			Checks:          []installer.Check{installer.BinaryExists("wl-copy")},
+			InstallCommands: []string{"sudo apt-get install -y wl-clipboard"},
+		}
+	case "x11":
+		return installer.InstallCommand{
+			Name:            "clipboard tool (xclip)",
+			Checks:          []installer.Check{installer.BinaryExists("xclip")},
+			InstallCommands: []string{"sudo apt-get install -y xclip"},
+		}
+	default:
+		return installer.InstallCommand{
+			Name:          "clipboard tool (headless, nothing to do)",
+			CheckCommands: []string{"true"},
+		}
+	}
+}
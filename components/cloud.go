@@ -0,0 +1,96 @@
+package components
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// AWSCLI installs AWS CLI v2 from the official installer and sets the
+// default region on the given profile. With login, an `aws sso login`
+// device flow is kicked off at the end of the install.
+func AWSCLI(region, profile string, login bool) installer.InstallCommand {
+	arch := "x86_64"
+	if runtime.GOARCH == "arm64" {
+		arch = "aarch64"
+	}
+	url := fmt.Sprintf("https://awscli.amazonaws.com/awscli-exe-linux-%s.zip", arch)
+	if profile == "" {
+		profile = "default"
+	}
+	commands := []string{
+		fmt.Sprintf("test -s /tmp/awscliv2.zip || curl -fsSL -o /tmp/awscliv2.zip %q", url),
+		"unzip -o -q /tmp/awscliv2.zip -d /tmp",
+		"sudo /tmp/aws/install --update",
+	}
+	if region != "" {
+		commands = append(commands, fmt.Sprintf("aws configure set region %s --profile %s", region, profile))
+	}
+	if login {
+		commands = append(commands, fmt.Sprintf("aws sso login --profile %s", profile))
+	}
+	return installer.InstallCommand{
+		Name:           "AWS CLI",
+		License:        "Apache-2.0",
+		Domains:        []string{"awscli.amazonaws.com"},
+		VersionCommand: "aws --version",
+		Checks:         []installer.Check{installer.BinaryExists("aws")},
+		Downloads: []installer.Download{
+			{URL: url, Dest: "/tmp/awscliv2.zip"},
+		},
+		InstallCommands: commands,
+	}
+}
+
+// GCloud installs the Google Cloud CLI from Google's apt repository
+// and sets the default project. With login, a no-browser auth flow is
+// kicked off at the end of the install.
+func GCloud(project string, login bool) installer.InstallCommand {
+	commands := []string{
+		"curl -fsSL https://packages.cloud.google.com/apt/doc/apt-key.gpg | sudo gpg --dearmor -o /usr/share/keyrings/cloud.google.gpg --yes",
+		`echo "deb [signed-by=/usr/share/keyrings/cloud.google.gpg] https://packages.cloud.google.com/apt cloud-sdk main" | sudo tee /etc/apt/sources.list.d/google-cloud-sdk.list > /dev/null`,
+		"sudo apt-get update",
+		"sudo apt-get install -y google-cloud-cli",
+	}
+	if project != "" {
+		commands = append(commands, fmt.Sprintf("gcloud config set project %s", project))
+	}
+	if login {
+		commands = append(commands, "gcloud auth login --no-launch-browser")
+	}
+	return installer.InstallCommand{
+		Name:            "Google Cloud CLI",
+		License:         "Proprietary",
+		Domains:         []string{"packages.cloud.google.com"},
+		VersionCommand:  "gcloud --version",
+		LatestVersion:   aptCandidate("google-cloud-cli"),
+		Checks:          []installer.Check{installer.BinaryExists("gcloud")},
+		InstallCommands: commands,
+	}
+}
+
+// AzureCLI installs az via Microsoft's official install script (pinned
+// and diffed like the NodeSource one) and sets the default location.
+// With login, a device-code flow is kicked off at the end of the
+// install.
+func AzureCLI(location string, login bool) installer.InstallCommand {
+	var commands []string
+	if location != "" {
+		commands = append(commands, fmt.Sprintf("az configure --defaults location=%s", location))
+	}
+	if login {
+		commands = append(commands, "az login --use-device-code")
+	}
+	return installer.InstallCommand{
+		Name:           "Azure CLI",
+		License:        "MIT",
+		Domains:        []string{"aka.ms", "packages.microsoft.com"},
+		VersionCommand: "az version",
+		Checks:         []installer.Check{installer.BinaryExists("az")},
+		VerifiedScripts: []installer.VerifiedScript{
+			{URL: "https://aka.ms/InstallAzureCLIDeb", Sudo: true},
+		},
+		InstallCommands: commands,
+	}
+}
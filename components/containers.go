@@ -0,0 +1,47 @@
+package components
+
+import "github.com/ishida722/setup/installer"
+
+// Docker installs Docker Engine from Docker's apt repository and puts
+// the user in the docker group (effective after re-login).
+func Docker() installer.InstallCommand {
+	return installer.InstallCommand{
+		Name:           "Docker",
+		License:        "Apache-2.0",
+		ConflictsWith:  []string{"Podman"},
+		Domains:        []string{"download.docker.com"},
+		VersionCommand: "docker --version",
+		LatestVersion:  aptCandidate("docker-ce"),
+		Checks:         []installer.Check{installer.BinaryExists("docker")},
+		InstallCommands: []string{
+			"curl -fsSL https://download.docker.com/linux/ubuntu/gpg | sudo gpg --dearmor -o /usr/share/keyrings/docker.gpg --yes",
+			`echo "deb [arch=$(dpkg --print-architecture) signed-by=/usr/share/keyrings/docker.gpg] https://download.docker.com/linux/ubuntu $(. /etc/os-release && echo $VERSION_CODENAME) stable" | sudo tee /etc/apt/sources.list.d/docker.list > /dev/null`,
+			"sudo apt-get update",
+			"sudo apt-get install -y docker-ce docker-ce-cli containerd.io docker-compose-plugin",
+			"sudo systemctl enable --now docker",
+			`sudo usermod -aG docker "$USER"`,
+		},
+	}
+}
+
+// Podman installs Podman with podman-compose and the podman-docker
+// shim (a docker CLI that talks to Podman), configured for rootless
+// use: subuid/subgid ranges for the user and a storage migration so
+// existing rootless state picks them up.
+func Podman() installer.InstallCommand {
+	return installer.InstallCommand{
+		Name:           "Podman",
+		License:        "Apache-2.0",
+		ConflictsWith:  []string{"Docker"},
+		VersionCommand: "podman --version",
+		LatestVersion:  aptCandidate("podman"),
+		Checks:         []installer.Check{installer.BinaryExists("podman")},
+		InstallCommands: []string{
+			"sudo apt-get install -y podman podman-compose podman-docker uidmap slirp4netns",
+			`grep -q "^$USER:" /etc/subuid || echo "$USER:100000:65536" | sudo tee -a /etc/subuid > /dev/null`,
+			`grep -q "^$USER:" /etc/subgid || echo "$USER:100000:65536" | sudo tee -a /etc/subgid > /dev/null`,
+			"podman system migrate",
+		},
+		PostCheckCommands: []string{"podman info > /dev/null"},
+	}
+}
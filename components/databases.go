@@ -0,0 +1,55 @@
+package components
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// PostgreSQL installs PostgreSQL from apt, enables the service, and
+// creates a development role and database. The role defaults to the
+// login user so peer authentication lets plain `psql` connect without
+// any password handling.
+func PostgreSQL(user, db string) installer.InstallCommand {
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+	if db == "" {
+		db = user
+	}
+	return installer.InstallCommand{
+		Name: "PostgreSQL",
+		CheckCommands: []string{
+			"systemctl is-active --quiet postgresql",
+			fmt.Sprintf(`sudo -u postgres psql -tAc "SELECT 1 FROM pg_database WHERE datname='%s'" | grep -q 1`, db),
+		},
+		InstallCommands: []string{
+			"sudo apt-get install -y postgresql",
+			"sudo systemctl enable --now postgresql",
+			fmt.Sprintf(`sudo -u postgres psql -tAc "SELECT 1 FROM pg_roles WHERE rolname='%s'" | grep -q 1 || sudo -u postgres createuser --createdb %s`, user, user),
+			fmt.Sprintf(`sudo -u postgres psql -tAc "SELECT 1 FROM pg_database WHERE datname='%s'" | grep -q 1 || sudo -u postgres createdb -O %s %s`, db, user, db),
+		},
+		PostCheckCommands: []string{
+			fmt.Sprintf(`psql -d %s -tAc 'SELECT 1' | grep -q 1`, db),
+		},
+	}
+}
+
+// Redis installs redis-server from apt with a development-sized memory
+// cap and enables the service.
+func Redis() installer.InstallCommand {
+	return installer.InstallCommand{
+		Name:          "Redis",
+		CheckCommands: []string{"systemctl is-active --quiet redis-server"},
+		InstallCommands: []string{
+			"sudo apt-get install -y redis-server",
+			// A bounded cache beats an unbounded one on a laptop; the
+			// sed is idempotent against both the stock comment and a
+			// previous run.
+			`sudo sed -i 's/^# maxmemory <bytes>/maxmemory 256mb/' /etc/redis/redis.conf`,
+			"sudo systemctl enable --now redis-server",
+		},
+		PostCheckCommands: []string{"redis-cli ping | grep -q PONG"},
+	}
+}
@@ -0,0 +1,245 @@
+package components
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+)
+
+// Dotfile declares one managed configuration repository. Without
+// Source the repository is cloned directly at Dest; with Source the
+// repository lives under ~/.dotfiles and Dest becomes a symlink to
+// that path inside the clone, so several entries can share one
+// dotfiles monorepo.
+type Dotfile struct {
+	URL    string
+	Dest   string // relative to $HOME
+	Source string // optional path inside the repository to link Dest at
+}
+
+// DefaultDotfiles are the personal configuration repositories from
+// the playbook.
+var DefaultDotfiles = []Dotfile{
+	{URL: "https://github.com/ishida722/nvim", Dest: ".config/nvim"},
+	{URL: "https://github.com/ishida722/fish", Dest: ".config/fish"},
+	{URL: "https://github.com/ishida722/krapp-config", Dest: ".config/krapp"},
+}
+
+// CustomDotfiles, when non-empty, replaces DefaultDotfiles for the
+// catalog's configuration component; main fills it from -dotfiles.
+var CustomDotfiles []Dotfile
+
+// ParseDotfiles parses semicolon-separated entries of the form
+// "url dest [source]", e.g.
+// "https://github.com/me/dots .config/nvim nvim".
+func ParseDotfiles(spec string) ([]Dotfile, error) {
+	var entries []Dotfile
+	for _, entry := range strings.Split(spec, ";") {
+		fields := strings.Fields(entry)
+		switch len(fields) {
+		case 0:
+			continue
+		case 2:
+			entries = append(entries, Dotfile{URL: fields[0], Dest: fields[1]})
+		case 3:
+			entries = append(entries, Dotfile{URL: fields[0], Dest: fields[1], Source: fields[2]})
+		default:
+			return nil, fmt.Errorf("dotfile entry %q: want \"url dest [source]\"", strings.TrimSpace(entry))
+		}
+	}
+	return entries, nil
+}
+
+// Dotfiles manages the configuration repositories: missing clones are
+// created, existing ones fast-forwarded, declared symlinks maintained,
+// and anything in the way is moved to a timestamped backup directory
+// first. A nil list means CustomDotfiles or DefaultDotfiles.
+func Dotfiles(entries []Dotfile) installer.InstallCommand {
+	if len(entries) == 0 {
+		entries = CustomDotfiles
+	}
+	if len(entries) == 0 {
+		entries = DefaultDotfiles
+	}
+	return installer.InstallCommand{
+		Name:        "configuration repositories",
+		Domains:     []string{"github.com"},
+		InstallFunc: func() error { return syncDotfiles(entries) },
+	}
+}
+
+// cloneDir returns where the entry's repository is (or will be)
+// checked out.
+func (d Dotfile) cloneDir(home string) string {
+	if d.Source == "" {
+		return filepath.Join(home, d.Dest)
+	}
+	return filepath.Join(home, ".dotfiles", strings.TrimSuffix(path.Base(d.URL), ".git"))
+}
+
+// syncDotfiles converges every entry concurrently and aggregates the
+// per-entry outcomes into one error, then scans the clones for leaked
+// credentials like the old clone-only implementation did.
+func syncDotfiles(entries []Dotfile) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	stamp := time.Now().Format("20060102-150405")
+	sem := make(chan struct{}, cloneConcurrency)
+	errs := make([]error, len(entries))
+	var wg sync.WaitGroup
+	for i, d := range entries {
+		i, d := i, d
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			errs[i] = syncDotfile(d, home, stamp)
+		}()
+	}
+	wg.Wait()
+	var failed []string
+	for i, err := range errs {
+		if err != nil {
+			logger.Error("dotfiles %s: %v", entries[i].URL, err)
+			failed = append(failed, entries[i].URL)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d dotfile entries failed: %s",
+			len(failed), len(entries), strings.Join(failed, ", "))
+	}
+	return scanDotfilesForSecrets(entries, home)
+}
+
+// syncDotfile converges one entry: clone or fast-forward the
+// repository, then point the declared symlink at it.
+func syncDotfile(d Dotfile, home, stamp string) error {
+	clone := d.cloneDir(home)
+	if _, err := os.Stat(filepath.Join(clone, ".git")); err != nil {
+		if err := backupExisting(clone, home, stamp); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(clone), 0o755); err != nil {
+			return err
+		}
+		cloneCmd := "git clone"
+		if ShallowClones {
+			cloneCmd += " --depth=1 --filter=blob:none"
+		}
+		if err := installer.Run(fmt.Sprintf("%s %q %q", cloneCmd, d.URL, clone)); err != nil {
+			return err
+		}
+	} else if err := installer.Run(fmt.Sprintf("git -C %q pull --ff-only", clone)); err != nil {
+		return fmt.Errorf("update failed (local changes or diverged history?): %w", err)
+	}
+	if d.Source == "" {
+		return nil
+	}
+	target := filepath.Join(home, d.Dest)
+	want := filepath.Join(clone, d.Source)
+	if existing, err := os.Readlink(target); err == nil && existing == want {
+		return nil
+	}
+	if err := backupExisting(target, home, stamp); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	return os.Symlink(want, target)
+}
+
+// backupExisting moves whatever is at path into this run's timestamped
+// backup directory, so a conflicting local file is never lost, only
+// relocated. A missing path is fine.
+func backupExisting(path, home, stamp string) error {
+	if _, err := os.Lstat(path); err != nil {
+		return nil
+	}
+	dir := filepath.Join(home, ".dotfiles-backup", stamp)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	dest := filepath.Join(dir, filepath.Base(path))
+	logger.Warn("moving %s aside to %s", path, dest)
+	return os.Rename(path, dest)
+}
+
+// scanDotfilesForSecrets checks the clones for leaked credentials
+// before their contents get used, warning by default and failing the
+// component when SecretScanBlock is set.
+func scanDotfilesForSecrets(entries []Dotfile, home string) error {
+	total := 0
+	for _, d := range entries {
+		findings, err := ScanSecrets(d.cloneDir(home))
+		if err != nil {
+			logger.Warn("secret scan of %s: %v", d.Dest, err)
+			continue
+		}
+		for _, f := range findings {
+			logger.Warn("%s", f)
+		}
+		total += len(findings)
+	}
+	if total > 0 && SecretScanBlock {
+		return fmt.Errorf("%d suspected credentials in configuration repositories (see warnings above)", total)
+	}
+	return nil
+}
+
+// DotfileState is one entry's drift summary for `setup dotfiles
+// status`.
+type DotfileState struct {
+	Dest  string
+	State string // "ok", "missing", "local changes", "wrong link", ...
+}
+
+// DotfilesStatus reports each entry's drift without changing anything.
+func DotfilesStatus(entries []Dotfile) []DotfileState {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	states := make([]DotfileState, 0, len(entries))
+	for _, d := range entries {
+		states = append(states, DotfileState{Dest: d.Dest, State: dotfileState(d, home)})
+	}
+	return states
+}
+
+func dotfileState(d Dotfile, home string) string {
+	clone := d.cloneDir(home)
+	if _, err := os.Stat(filepath.Join(clone, ".git")); err != nil {
+		if _, err := os.Lstat(clone); err == nil {
+			return "exists but is not a clone"
+		}
+		return "missing"
+	}
+	var buf strings.Builder
+	if err := installer.RunTo(fmt.Sprintf("git -C %q status --porcelain", clone), &buf, io.Discard); err == nil &&
+		strings.TrimSpace(buf.String()) != "" {
+		return "local changes"
+	}
+	if d.Source != "" {
+		target := filepath.Join(home, d.Dest)
+		existing, err := os.Readlink(target)
+		switch {
+		case err != nil:
+			return "link missing"
+		case existing != filepath.Join(clone, d.Source):
+			return "wrong link"
+		}
+	}
+	return "ok"
+}
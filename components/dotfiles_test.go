@@ -0,0 +1,95 @@
+package components
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseDotfiles(t *testing.T) {
+	entries, err := ParseDotfiles("https://github.com/me/nvim .config/nvim; https://github.com/me/dots .config/fish fish")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Source != "" || entries[1].Source != "fish" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if _, err := ParseDotfiles("https://github.com/me/dots"); err == nil {
+		t.Fatal("entry without dest accepted")
+	}
+}
+
+// gitDotfilesRepo builds a local repository to clone from.
+func gitDotfilesRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "test"},
+	} {
+		run := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if out, err := run.CombinedOutput(); err != nil {
+			t.Skipf("git unavailable: %v (%s)", err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "init.lua"), []byte("-- v1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "-C", dir, "add", "-A").CombinedOutput(); err != nil {
+		t.Fatalf("git add: %v (%s)", err, out)
+	}
+	if out, err := exec.Command("git", "-C", dir, "commit", "-qm", "v1").CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v (%s)", err, out)
+	}
+	return dir
+}
+
+func TestSyncDotfilesClonesUpdatesAndBacksUp(t *testing.T) {
+	repo := gitDotfilesRepo(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// Something non-git already sits at the destination: it must be
+	// moved aside, not lost.
+	dest := filepath.Join(home, ".config", "nvim")
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dest, "local.lua"), []byte("mine\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := []Dotfile{{URL: repo, Dest: ".config/nvim"}}
+	if err := syncDotfiles(entries); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "init.lua")); err != nil {
+		t.Fatalf("clone missing: %v", err)
+	}
+	backups, err := filepath.Glob(filepath.Join(home, ".dotfiles-backup", "*", "nvim", "local.lua"))
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("conflicting file not backed up: %v %v", backups, err)
+	}
+
+	// A new upstream commit arrives on the next sync.
+	if err := os.WriteFile(filepath.Join(repo, "init.lua"), []byte("-- v2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "-C", repo, "commit", "-qam", "v2").CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v (%s)", err, out)
+	}
+	if err := syncDotfiles(entries); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dest, "init.lua"))
+	if string(data) != "-- v2\n" {
+		t.Fatalf("existing clone not updated: %q", data)
+	}
+
+	states := DotfilesStatus(entries)
+	if len(states) != 1 || states[0].State != "ok" {
+		t.Fatalf("status after sync: %+v", states)
+	}
+}
@@ -0,0 +1,58 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// FlatpakApp declares one Flathub application with optional per-app
+// sandbox overrides (flatpak override flags like "--filesystem=home").
+type FlatpakApp struct {
+	ID        string
+	Overrides []string
+}
+
+// ParseFlatpakApps parses semicolon-separated app entries, each an app
+// ID followed by override flags, e.g.
+// "com.slack.Slack;com.obsproject.Studio --device=all".
+func ParseFlatpakApps(spec string) ([]FlatpakApp, error) {
+	var apps []FlatpakApp
+	for _, entry := range strings.Split(spec, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		if !strings.Contains(fields[0], ".") {
+			return nil, fmt.Errorf("flatpak entry %q: %q does not look like an app ID", entry, fields[0])
+		}
+		apps = append(apps, FlatpakApp{ID: fields[0], Overrides: fields[1:]})
+	}
+	return apps, nil
+}
+
+// Flatpak ensures flatpak with the Flathub remote and installs the
+// declared applications with their overrides. Per-app checks make
+// adding an app to the list an incremental re-run.
+func Flatpak(apps []FlatpakApp) installer.InstallCommand {
+	var checkCommands []string
+	commands := []string{
+		"sudo apt-get install -y flatpak",
+		"sudo flatpak remote-add --if-not-exists flathub https://flathub.org/repo/flathub.flatpakrepo",
+	}
+	for _, app := range apps {
+		checkCommands = append(checkCommands, fmt.Sprintf("flatpak info %s > /dev/null", app.ID))
+		commands = append(commands, fmt.Sprintf("flatpak install -y --noninteractive flathub %s", app.ID))
+		for _, override := range app.Overrides {
+			commands = append(commands, fmt.Sprintf("flatpak override --user %s %s", app.ID, override))
+		}
+	}
+	return installer.InstallCommand{
+		Name:            "Flatpak applications",
+		Domains:         []string{"flathub.org", "dl.flathub.org"},
+		Checks:          []installer.Check{installer.BinaryExists("flatpak")},
+		CheckCommands:   checkCommands,
+		InstallCommands: commands,
+	}
+}
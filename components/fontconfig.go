@@ -0,0 +1,55 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// japaneseFontconf prefers the Noto CJK JP faces for the generic
+// families and pins the JP Han variants when the language is ambiguous,
+// so unified CJK codepoints render with Japanese glyphs instead of
+// whichever region fontconfig guesses.
+const japaneseFontconf = `<?xml version="1.0"?>
+<!DOCTYPE fontconfig SYSTEM "fonts.dtd">
+<fontconfig>
+  <alias>
+    <family>sans-serif</family>
+    <prefer><family>Noto Sans CJK JP</family></prefer>
+  </alias>
+  <alias>
+    <family>serif</family>
+    <prefer><family>Noto Serif CJK JP</family></prefer>
+  </alias>
+  <alias>
+    <family>monospace</family>
+    <prefer><family>Noto Sans Mono CJK JP</family></prefer>
+  </alias>
+  <match target="pattern">
+    <test name="lang" compare="contains"><string>ja</string></test>
+    <edit name="family" mode="prepend"><string>Noto Sans CJK JP</string></edit>
+  </match>
+</fontconfig>
+`
+
+// japaneseFontconfPath is relative to $HOME; conf.d entries sort by
+// prefix and 70- runs after the distro defaults.
+const japaneseFontconfPath = `.config/fontconfig/conf.d/70-japanese.conf`
+
+// JapaneseFonts installs the Noto CJK fonts, writes the fontconfig
+// rules above for the user, and refreshes the font cache.
+func JapaneseFonts() installer.InstallCommand {
+	return installer.InstallCommand{
+		Name: "Japanese fonts and fontconfig",
+		CheckCommands: []string{
+			"dpkg -s fonts-noto-cjk > /dev/null",
+			fmt.Sprintf(`test -f "$HOME/%s"`, japaneseFontconfPath),
+		},
+		InstallCommands: []string{
+			"sudo apt-get install -y fonts-noto-cjk fonts-noto-cjk-extra",
+			`mkdir -p "$HOME/.config/fontconfig/conf.d"`,
+			fmt.Sprintf(`printf '%%s' %s > "$HOME/%s"`, shellQuote(japaneseFontconf), japaneseFontconfPath),
+			"fc-cache -f",
+		},
+	}
+}
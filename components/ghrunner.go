@@ -0,0 +1,52 @@
+package components
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// actionsRunnerVersion pins the runner release; the runner self-updates
+// once registered.
+const actionsRunnerVersion = "2.319.1"
+
+// ActionsRunner downloads the GitHub Actions runner, registers it
+// against the repository or organization URL, installs its systemd
+// service, and verifies it is running. The registration token comes
+// from the repo's runner settings and is short-lived, so it is passed
+// per invocation (flag or RUNNER_TOKEN) rather than stored anywhere.
+func ActionsRunner(url, token, name string) installer.InstallCommand {
+	arch := "x64"
+	if runtime.GOARCH == "arm64" {
+		arch = "arm64"
+	}
+	tarball := fmt.Sprintf("actions-runner-linux-%s-%s.tar.gz", arch, actionsRunnerVersion)
+	download := fmt.Sprintf("https://github.com/actions/runner/releases/download/v%s/%s", actionsRunnerVersion, tarball)
+	dir := `"$HOME/actions-runner"`
+	nameFlag := ""
+	if name != "" {
+		nameFlag = fmt.Sprintf(" --name %q", name)
+	}
+	return installer.InstallCommand{
+		Name:    "GitHub Actions runner",
+		License: "MIT",
+		Domains: []string{"github.com", "objects.githubusercontent.com", "api.github.com"},
+		// .runner is written by config.sh once registration succeeds.
+		CheckCommands: []string{`test -f "$HOME/actions-runner/.runner"`},
+		Downloads: []installer.Download{
+			{URL: download, Dest: "/tmp/" + tarball},
+		},
+		InstallCommands: []string{
+			fmt.Sprintf("test -s /tmp/%s || curl -fsSL -o /tmp/%s %q", tarball, tarball, download),
+			fmt.Sprintf("mkdir -p %s", dir),
+			fmt.Sprintf("tar -xzf /tmp/%s -C %s", tarball, dir),
+			fmt.Sprintf("cd %s && ./config.sh --unattended --url %q --token %q%s", dir, url, token, nameFlag),
+			fmt.Sprintf("cd %s && sudo ./svc.sh install", dir),
+			fmt.Sprintf("cd %s && sudo ./svc.sh start", dir),
+		},
+		PostCheckCommands: []string{
+			fmt.Sprintf("cd %s && sudo ./svc.sh status | grep -q 'active (running)'", dir),
+		},
+	}
+}
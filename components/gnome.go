@@ -0,0 +1,64 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// GSetting is one declarative gsettings key; Value is in GVariant
+// syntax exactly as `gsettings set` expects it.
+type GSetting struct {
+	Schema, Key, Value string
+}
+
+// DefaultGSettings are the desktop preferences applied by -gnome: dark
+// theme, fast key repeat, caps as ctrl, and fractional scaling.
+var DefaultGSettings = []GSetting{
+	{"org.gnome.desktop.interface", "color-scheme", "'prefer-dark'"},
+	{"org.gnome.desktop.peripherals.keyboard", "repeat-interval", "uint32 25"},
+	{"org.gnome.desktop.peripherals.keyboard", "delay", "uint32 250"},
+	{"org.gnome.desktop.input-sources", "xkb-options", "['ctrl:nocaps']"},
+	{"org.gnome.mutter", "experimental-features", "['scale-monitor-framebuffer']"},
+}
+
+// ParseGSettings parses semicolon-separated "schema key value"
+// entries; everything after the key is the GVariant value.
+func ParseGSettings(spec string) ([]GSetting, error) {
+	var settings []GSetting
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		fields := strings.Fields(entry)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("gsettings entry %q: want \"schema key value\"", entry)
+		}
+		settings = append(settings, GSetting{fields[0], fields[1], strings.Join(fields[2:], " ")})
+	}
+	return settings, nil
+}
+
+// GnomeSettings applies the gsettings keys and optionally loads a
+// dconf dump, so desktop preferences are code like everything else.
+// Each key has its own check, so drift in one setting re-applies only
+// this component.
+func GnomeSettings(settings []GSetting, dconfDump string) installer.InstallCommand {
+	var checks, commands []string
+	for _, s := range settings {
+		checks = append(checks,
+			fmt.Sprintf(`test "$(gsettings get %s %s)" = %s`, s.Schema, s.Key, shellQuote(s.Value)))
+		commands = append(commands,
+			fmt.Sprintf("gsettings set %s %s %s", s.Schema, s.Key, shellQuote(s.Value)))
+	}
+	if dconfDump != "" {
+		commands = append(commands, fmt.Sprintf("dconf load / < %q", dconfDump))
+	}
+	return installer.InstallCommand{
+		Name:            "GNOME settings",
+		CheckCommands:   checks,
+		InstallCommands: commands,
+	}
+}
@@ -0,0 +1,33 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+)
+
+// UserGroups ensures the provisioning user is in each of the given
+// supplementary groups (e.g. docker, dialout, libvirt, video). Groups
+// that do not exist yet are skipped, so the step can run before or
+// after the component that creates them. Membership only takes effect
+// after re-login, which the step reports.
+func UserGroups(groups []string) installer.InstallCommand {
+	var checks, commands []string
+	for _, g := range groups {
+		// In the group already, or the group does not exist yet.
+		checks = append(checks, fmt.Sprintf(
+			`! getent group %[1]s > /dev/null || id -nG "$USER" | tr ' ' '\n' | grep -qx %[1]s`, g))
+		commands = append(commands, fmt.Sprintf(
+			`! getent group %[1]s > /dev/null || sudo usermod -aG %[1]s "$USER"`, g))
+	}
+	return installer.InstallCommand{
+		Name:            "supplementary groups",
+		CheckCommands:   checks,
+		InstallCommands: commands,
+		InstallFunc: func() error {
+			logger.Info("group membership changes take effect after re-login")
+			return nil
+		},
+	}
+}
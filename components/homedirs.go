@@ -0,0 +1,39 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// DefaultHomeDirs is the standard home directory layout, relative to
+// $HOME.
+var DefaultHomeDirs = []string{"src", "bin", "notes", ".local/bin"}
+
+// HomeSkeleton creates the home directory layout with 0755 permissions
+// and, when readme is set, drops a README placeholder into each
+// directory describing what belongs there.
+func HomeSkeleton(dirs []string, readme bool) installer.InstallCommand {
+	if len(dirs) == 0 {
+		dirs = DefaultHomeDirs
+	}
+	var checks, commands []string
+	for _, d := range dirs {
+		path := "~/" + d
+		checks = append(checks, "test -d "+path)
+		commands = append(commands,
+			"mkdir -p "+path,
+			"chmod 755 "+path,
+		)
+		if readme {
+			checks = append(checks, fmt.Sprintf("test -f %s/README.md", path))
+			commands = append(commands,
+				fmt.Sprintf("test -f %s/README.md || printf '# %s\\n' > %s/README.md", path, d, path))
+		}
+	}
+	return installer.InstallCommand{
+		Name:            "home directory skeleton",
+		CheckCommands:   checks,
+		InstallCommands: commands,
+	}
+}
@@ -0,0 +1,53 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// HostnameOptions control the hostname step. Either Name is given
+// directly, or it is rendered from Template with the {role}, {location}
+// and {index} placeholders (e.g. "{role}-{location}-{index}" ->
+// "web-tokyo-1").
+type HostnameOptions struct {
+	Name     string
+	Template string
+	Role     string
+	Location string
+	Index    int
+}
+
+// Hostname returns the hostname the options resolve to, or "" if the
+// step is not configured.
+func (o HostnameOptions) Hostname() string {
+	if o.Name != "" {
+		return o.Name
+	}
+	if o.Template == "" {
+		return ""
+	}
+	r := strings.NewReplacer(
+		"{role}", o.Role,
+		"{location}", o.Location,
+		"{index}", fmt.Sprintf("%d", o.Index),
+	)
+	return r.Replace(o.Template)
+}
+
+// Hostname sets the machine hostname via hostnamectl and keeps the
+// 127.0.1.1 entry in /etc/hosts in sync, typically the first step on a
+// fresh VPS.
+func Hostname(opts HostnameOptions) installer.InstallCommand {
+	name := opts.Hostname()
+	return installer.InstallCommand{
+		Name:          "hostname",
+		CheckCommands: []string{fmt.Sprintf(`test "$(hostname)" = %q`, name)},
+		InstallCommands: []string{
+			fmt.Sprintf("sudo hostnamectl set-hostname %q", name),
+			fmt.Sprintf(`sudo sed -i 's/^127\.0\.1\.1[[:space:]].*/127.0.1.1\t%s/' /etc/hosts`, name),
+			fmt.Sprintf(`grep -q '^127\.0\.1\.1' /etc/hosts || printf '127.0.1.1\t%s\n' | sudo tee -a /etc/hosts > /dev/null`, name),
+		},
+	}
+}
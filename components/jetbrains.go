@@ -0,0 +1,53 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// jetbrainsToolboxVersion pins the Toolbox tarball; Toolbox keeps
+// itself up to date once installed.
+const jetbrainsToolboxVersion = "2.4.2.32922"
+
+// jetbrainsDesktopEntry is the launcher entry; __HOME__ is substituted
+// at install time because .desktop files do not expand variables.
+const jetbrainsDesktopEntry = `[Desktop Entry]
+Name=JetBrains Toolbox
+Exec=__HOME__/.local/share/JetBrains/Toolbox/bin/jetbrains-toolbox
+Type=Application
+Terminal=false
+Categories=Development;IDE;
+`
+
+// JetBrainsToolbox installs JetBrains Toolbox into ~/.local/share with
+// a desktop entry, and pre-installs the given IDEs (e.g.
+// "intellij-idea-ultimate") through its CLI.
+func JetBrainsToolbox(ides []string) installer.InstallCommand {
+	url := fmt.Sprintf("https://download.jetbrains.com/toolbox/jetbrains-toolbox-%s.tar.gz", jetbrainsToolboxVersion)
+	bin := `"$HOME/.local/share/JetBrains/Toolbox/bin"`
+	commands := []string{
+		fmt.Sprintf("test -s /tmp/jetbrains-toolbox.tar.gz || curl -fsSL -o /tmp/jetbrains-toolbox.tar.gz %q", url),
+		fmt.Sprintf("mkdir -p %s", bin),
+		fmt.Sprintf("tar -xzf /tmp/jetbrains-toolbox.tar.gz --strip-components=1 -C %s", bin),
+		`mkdir -p "$HOME/.local/bin"`,
+		fmt.Sprintf(`ln -sf %s/jetbrains-toolbox "$HOME/.local/bin/jetbrains-toolbox"`, bin),
+		`mkdir -p "$HOME/.local/share/applications"`,
+		fmt.Sprintf(`printf '%%s' %s > "$HOME/.local/share/applications/jetbrains-toolbox.desktop"`,
+			shellQuote(jetbrainsDesktopEntry)),
+		`sed -i "s|__HOME__|$HOME|g" "$HOME/.local/share/applications/jetbrains-toolbox.desktop"`,
+	}
+	for _, ide := range ides {
+		commands = append(commands, fmt.Sprintf(`"$HOME/.local/bin/jetbrains-toolbox" install %q`, ide))
+	}
+	return installer.InstallCommand{
+		Name:          "JetBrains Toolbox",
+		License:       "Proprietary",
+		Domains:       []string{"download.jetbrains.com"},
+		CheckCommands: []string{`test -x "$HOME/.local/share/JetBrains/Toolbox/bin/jetbrains-toolbox"`},
+		Downloads: []installer.Download{
+			{URL: url, Dest: "/tmp/jetbrains-toolbox.tar.gz"},
+		},
+		InstallCommands: commands,
+	}
+}
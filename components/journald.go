@@ -0,0 +1,28 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// Journald caps journal disk usage and switches storage to persistent
+// via a drop-in — the defaults have filled small VPS disks before.
+func Journald(maxUse string) installer.InstallCommand {
+	conf := fmt.Sprintf(`[Journal]
+Storage=persistent
+SystemMaxUse=%s
+`, maxUse)
+	const path = "/etc/systemd/journald.conf.d/setup.conf"
+	return installer.InstallCommand{
+		Name: "journald configuration",
+		CheckCommands: []string{
+			fmt.Sprintf("cmp -s %s <(printf '%%s' %s)", path, shellQuote(conf)),
+		},
+		InstallCommands: []string{
+			"sudo mkdir -p /etc/systemd/journald.conf.d",
+			fmt.Sprintf("printf '%%s' %s | sudo tee %s > /dev/null", shellQuote(conf), path),
+			"sudo systemctl restart systemd-journald",
+		},
+	}
+}
@@ -0,0 +1,135 @@
+package components
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// Pinned Kubernetes tooling versions. Everyone on a team gets the same
+// binaries; bump these deliberately, together with the cluster.
+const (
+	kubectlVersion = "v1.31.1"
+	helmVersion    = "v3.16.1"
+	k9sVersion     = "v0.32.5"
+	kindVersion    = "v0.24.0"
+)
+
+// K8sTools returns the Kubernetes tooling bundle: kubectl, helm, k9s,
+// and kind at pinned versions from their official releases, plus fish
+// completions for all four.
+func K8sTools() []installer.InstallCommand {
+	return []installer.InstallCommand{
+		Kubectl(),
+		Helm(),
+		K9s(),
+		Kind(),
+		k8sFishCompletions(),
+	}
+}
+
+// Kubectl installs the pinned kubectl release from dl.k8s.io.
+func Kubectl() installer.InstallCommand {
+	url := fmt.Sprintf("https://dl.k8s.io/release/%s/bin/linux/%s/kubectl", kubectlVersion, runtime.GOARCH)
+	return installer.InstallCommand{
+		Name:           "kubectl",
+		License:        "Apache-2.0",
+		Domains:        []string{"dl.k8s.io"},
+		VersionCommand: "kubectl version --client",
+		LatestVersion:  githubLatestTag("kubernetes/kubernetes"),
+		Binaries:       []string{"/usr/local/bin/kubectl"},
+		Checks:         []installer.Check{installer.BinaryExists("kubectl")},
+		Downloads: []installer.Download{
+			{URL: url, Dest: "/tmp/kubectl"},
+		},
+		InstallCommands: []string{
+			fmt.Sprintf("test -s /tmp/kubectl || curl -fsSL -o /tmp/kubectl %q", url),
+			"sudo install -m 755 /tmp/kubectl /usr/local/bin/kubectl",
+		},
+	}
+}
+
+// Helm installs the pinned helm release from get.helm.sh.
+func Helm() installer.InstallCommand {
+	tarball := fmt.Sprintf("helm-%s-linux-%s.tar.gz", helmVersion, runtime.GOARCH)
+	url := "https://get.helm.sh/" + tarball
+	return installer.InstallCommand{
+		Name:           "helm",
+		License:        "Apache-2.0",
+		Domains:        []string{"get.helm.sh"},
+		VersionCommand: "helm version --short",
+		LatestVersion:  githubLatestTag("helm/helm"),
+		GitHubRepo:     "helm/helm",
+		Binaries:       []string{"/usr/local/bin/helm"},
+		Checks:         []installer.Check{installer.BinaryExists("helm")},
+		Downloads: []installer.Download{
+			{URL: url, Dest: "/tmp/" + tarball},
+		},
+		InstallCommands: []string{
+			fmt.Sprintf("test -s /tmp/%s || curl -fsSL -o /tmp/%s %q", tarball, tarball, url),
+			fmt.Sprintf("tar -C /tmp -xzf /tmp/%s linux-%s/helm", tarball, runtime.GOARCH),
+			fmt.Sprintf("sudo install -m 755 /tmp/linux-%s/helm /usr/local/bin/helm", runtime.GOARCH),
+		},
+	}
+}
+
+// K9s installs the pinned k9s release binary.
+func K9s() installer.InstallCommand {
+	url := fmt.Sprintf("https://github.com/derailed/k9s/releases/download/%s/k9s_Linux_%s.tar.gz", k9sVersion, runtime.GOARCH)
+	return installer.InstallCommand{
+		Name:           "k9s",
+		License:        "Apache-2.0",
+		Domains:        []string{"github.com", "objects.githubusercontent.com"},
+		VersionCommand: "k9s version --short",
+		LatestVersion:  githubLatestTag("derailed/k9s"),
+		GitHubRepo:     "derailed/k9s",
+		Binaries:       []string{"/usr/local/bin/k9s"},
+		Checks:         []installer.Check{installer.BinaryExists("k9s")},
+		Downloads: []installer.Download{
+			{URL: url, Dest: "/tmp/k9s.tar.gz"},
+		},
+		InstallCommands: []string{
+			fmt.Sprintf("test -s /tmp/k9s.tar.gz || curl -fsSL -o /tmp/k9s.tar.gz %q", url),
+			"tar -C /tmp -xzf /tmp/k9s.tar.gz k9s",
+			"sudo install -m 755 /tmp/k9s /usr/local/bin/k9s",
+		},
+	}
+}
+
+// Kind installs the pinned kind release binary.
+func Kind() installer.InstallCommand {
+	url := fmt.Sprintf("https://github.com/kubernetes-sigs/kind/releases/download/%s/kind-linux-%s", kindVersion, runtime.GOARCH)
+	return installer.InstallCommand{
+		Name:           "kind",
+		License:        "Apache-2.0",
+		Domains:        []string{"github.com", "objects.githubusercontent.com"},
+		VersionCommand: "kind version",
+		LatestVersion:  githubLatestTag("kubernetes-sigs/kind"),
+		GitHubRepo:     "kubernetes-sigs/kind",
+		Binaries:       []string{"/usr/local/bin/kind"},
+		Checks:         []installer.Check{installer.BinaryExists("kind")},
+		Downloads: []installer.Download{
+			{URL: url, Dest: "/tmp/kind"},
+		},
+		InstallCommands: []string{
+			fmt.Sprintf("test -s /tmp/kind || curl -fsSL -o /tmp/kind %q", url),
+			"sudo install -m 755 /tmp/kind /usr/local/bin/kind",
+		},
+	}
+}
+
+// k8sFishCompletions generates fish completions for the bundle into the
+// user's fish config; regenerated on every version bump because the
+// check is against the pinned binaries being present.
+func k8sFishCompletions() installer.InstallCommand {
+	var cmds []string
+	cmds = append(cmds, `mkdir -p "$HOME/.config/fish/completions"`)
+	for _, tool := range []string{"kubectl", "helm", "k9s", "kind"} {
+		cmds = append(cmds, fmt.Sprintf(`%s completion fish > "$HOME/.config/fish/completions/%s.fish"`, tool, tool))
+	}
+	return installer.InstallCommand{
+		Name:            "k8s fish completions",
+		InstallCommands: cmds,
+	}
+}
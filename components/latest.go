@@ -0,0 +1,92 @@
+package components
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// aptCandidate reports the version apt would install for pkg.
+func aptCandidate(pkg string) func() (string, error) {
+	return func() (string, error) {
+		var buf strings.Builder
+		if err := installer.RunTo("apt-cache policy "+pkg, &buf, io.Discard); err != nil {
+			return "", err
+		}
+		for _, line := range strings.Split(buf.String(), "\n") {
+			line = strings.TrimSpace(line)
+			if v, ok := strings.CutPrefix(line, "Candidate:"); ok {
+				v = strings.TrimSpace(v)
+				if v == "" || v == "(none)" {
+					return "", fmt.Errorf("no apt candidate for %s", pkg)
+				}
+				return v, nil
+			}
+		}
+		return "", fmt.Errorf("no apt candidate for %s", pkg)
+	}
+}
+
+// npmLatest reports the newest published version of a npm package.
+func npmLatest(pkg string) func() (string, error) {
+	return func() (string, error) {
+		var buf strings.Builder
+		if err := installer.RunTo(fmt.Sprintf("npm view %q version", pkg), &buf, io.Discard); err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(buf.String()), nil
+	}
+}
+
+// githubLatestPrerelease reports the tag of the newest prerelease;
+// nightly channels publish there, while /releases/latest only ever
+// shows stable builds.
+func githubLatestPrerelease(repo string) func() (string, error) {
+	return func() (string, error) {
+		resp, err := installer.HTTPClient.Get("https://api.github.com/repos/" + repo + "/releases?per_page=10")
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != 200 {
+			return "", fmt.Errorf("github: %s for %s", resp.Status, repo)
+		}
+		var releases []struct {
+			TagName    string `json:"tag_name"`
+			Prerelease bool   `json:"prerelease"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+			return "", err
+		}
+		for _, r := range releases {
+			if r.Prerelease {
+				return r.TagName, nil
+			}
+		}
+		return "", fmt.Errorf("no prerelease found for %s", repo)
+	}
+}
+
+// githubLatestTag reports the tag of a repository's latest release.
+func githubLatestTag(repo string) func() (string, error) {
+	return func() (string, error) {
+		resp, err := installer.HTTPClient.Get("https://api.github.com/repos/" + repo + "/releases/latest")
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != 200 {
+			return "", fmt.Errorf("github: %s for %s", resp.Status, repo)
+		}
+		var release struct {
+			TagName string `json:"tag_name"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+			return "", err
+		}
+		return release.TagName, nil
+	}
+}
@@ -0,0 +1,27 @@
+package components
+
+import "github.com/ishida722/setup/installer"
+
+// Libvirt installs the KVM/libvirt stack with virt-manager, puts the
+// user in the libvirt group, and autostarts the default NAT network —
+// the usual hand-run workstation commands, made idempotent. Group
+// membership takes effect on re-login, like the Fish shell change.
+func Libvirt() installer.InstallCommand {
+	return installer.InstallCommand{
+		Name: "KVM/libvirt stack",
+		CheckCommands: []string{
+			"systemctl is-active --quiet libvirtd",
+			`id -nG "$USER" | grep -qw libvirt`,
+		},
+		InstallCommands: []string{
+			// Fail early on machines without hardware virtualization.
+			"grep -Eq '(vmx|svm)' /proc/cpuinfo",
+			"sudo apt-get install -y qemu-kvm libvirt-daemon-system libvirt-clients virt-manager",
+			"sudo systemctl enable --now libvirtd",
+			`sudo usermod -aG libvirt "$USER"`,
+			"sudo virsh net-autostart default",
+			`sudo virsh net-info default | grep -q "Active:.*yes" || sudo virsh net-start default`,
+		},
+		PostCheckCommands: []string{"sudo virsh list > /dev/null"},
+	}
+}
@@ -0,0 +1,37 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// Limits raises the nofile/nproc limits via a limits.d drop-in for PAM
+// sessions and a system.conf.d drop-in for systemd services — needed
+// before installing databases or heavy dev servers on fresh machines.
+func Limits(nofile, nproc int) installer.InstallCommand {
+	pamConf := fmt.Sprintf(`* soft nofile %[1]d
+* hard nofile %[1]d
+* soft nproc %[2]d
+* hard nproc %[2]d
+`, nofile, nproc)
+	systemdConf := fmt.Sprintf(`[Manager]
+DefaultLimitNOFILE=%d
+DefaultLimitNPROC=%d
+`, nofile, nproc)
+	const pamPath = "/etc/security/limits.d/90-setup.conf"
+	const systemdPath = "/etc/systemd/system.conf.d/90-setup-limits.conf"
+	return installer.InstallCommand{
+		Name: "resource limits",
+		CheckCommands: []string{
+			fmt.Sprintf("cmp -s %s <(printf '%%s' %s)", pamPath, shellQuote(pamConf)),
+			fmt.Sprintf("cmp -s %s <(printf '%%s' %s)", systemdPath, shellQuote(systemdConf)),
+		},
+		InstallCommands: []string{
+			fmt.Sprintf("printf '%%s' %s | sudo tee %s > /dev/null", shellQuote(pamConf), pamPath),
+			"sudo mkdir -p /etc/systemd/system.conf.d",
+			fmt.Sprintf("printf '%%s' %s | sudo tee %s > /dev/null", shellQuote(systemdConf), systemdPath),
+			"sudo systemctl daemon-reexec",
+		},
+	}
+}
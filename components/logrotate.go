@@ -0,0 +1,40 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// SetupLogDir is where setup writes its own transcript logs.
+const SetupLogDir = "/var/log/setup"
+
+// LogrotateRule installs an /etc/logrotate.d drop-in rotating the
+// given glob weekly, so services installed by setup (and setup itself)
+// do not accumulate unbounded logs on long-lived machines.
+func LogrotateRule(name, glob string) installer.InstallCommand {
+	conf := fmt.Sprintf(`%s {
+    weekly
+    rotate 8
+    compress
+    delaycompress
+    missingok
+    notifempty
+}
+`, glob)
+	path := "/etc/logrotate.d/" + name
+	return installer.InstallCommand{
+		Name: "logrotate rule " + name,
+		CheckCommands: []string{
+			fmt.Sprintf("cmp -s %s <(printf '%%s' %s)", path, shellQuote(conf)),
+		},
+		InstallCommands: []string{
+			fmt.Sprintf("printf '%%s' %s | sudo tee %s > /dev/null", shellQuote(conf), path),
+		},
+	}
+}
+
+// Logrotate covers setup's own transcript logs.
+func Logrotate() installer.InstallCommand {
+	return LogrotateRule("setup", SetupLogDir+"/*.log")
+}
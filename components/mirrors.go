@@ -0,0 +1,129 @@
+package components
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// Mirrors overrides where components download from, for corporate
+// networks fronted by artifact proxies. Every backend is rewritten
+// consistently: command strings, prefetched downloads, verified
+// scripts, and the pre-flight domain list.
+type Mirrors struct {
+	// Apt replaces the Ubuntu archive mirror in the apt sources,
+	// e.g. "https://mirror.example.com/ubuntu".
+	Apt string
+	// Npm is the registry npm installs from,
+	// e.g. "https://artifactory.example.com/api/npm/npm".
+	Npm string
+	// GitHub replaces https://github.com/ in download URLs,
+	// e.g. "https://artifactory.example.com/github".
+	GitHub string
+}
+
+// ApplyMirrors rewrites the components for the configured mirrors.
+// With no overrides set the list is returned unchanged.
+func ApplyMirrors(m Mirrors, cmds []installer.InstallCommand) []installer.InstallCommand {
+	if m == (Mirrors{}) {
+		return cmds
+	}
+	out := make([]installer.InstallCommand, 0, len(cmds)+1)
+	if m.Apt != "" {
+		out = append(out, aptMirror(m.Apt))
+	}
+	for _, c := range cmds {
+		out = append(out, rewriteMirrors(m, c))
+	}
+	return out
+}
+
+// aptMirror points the Ubuntu archive entries at the mirror. Both the
+// classic sources.list and the deb822 ubuntu.sources layout are
+// handled; the sed is idempotent.
+func aptMirror(mirror string) installer.InstallCommand {
+	sed := fmt.Sprintf(`s|https\?://\(archive\|security\).ubuntu.com/ubuntu/\?|%s|g`, mirror)
+	return installer.InstallCommand{
+		Name: "apt mirror",
+		InstallCommands: []string{
+			fmt.Sprintf(`test ! -f /etc/apt/sources.list || sudo sed -i %q /etc/apt/sources.list`, sed),
+			fmt.Sprintf(`test ! -f /etc/apt/sources.list.d/ubuntu.sources || sudo sed -i %q /etc/apt/sources.list.d/ubuntu.sources`, sed),
+			"sudo apt-get update",
+		},
+	}
+}
+
+// rewriteMirrors applies the GitHub and npm overrides to one
+// component.
+func rewriteMirrors(m Mirrors, c installer.InstallCommand) installer.InstallCommand {
+	rewriteURL := func(s string) string {
+		if m.GitHub != "" {
+			s = strings.ReplaceAll(s, "https://github.com/", strings.TrimSuffix(m.GitHub, "/")+"/")
+		}
+		return s
+	}
+	for i, cmd := range c.InstallCommands {
+		cmd = rewriteURL(cmd)
+		if m.Npm != "" && strings.Contains(cmd, "npm install") {
+			cmd += fmt.Sprintf(" --registry=%s", m.Npm)
+		}
+		c.InstallCommands[i] = cmd
+	}
+	downloads := make([]installer.Download, len(c.Downloads))
+	for i, d := range c.Downloads {
+		// The upstream URL stays behind the mirror as a fallback, so a
+		// proxy outage degrades to a direct download instead of a
+		// failed run (a blocked upstream just fails over again).
+		if rewritten := rewriteURL(d.URL); rewritten != d.URL {
+			d.Mirrors = append(d.Mirrors, d.URL)
+			d.URL = rewritten
+		}
+		downloads[i] = d
+	}
+	c.Downloads = downloads
+	scripts := make([]installer.VerifiedScript, len(c.VerifiedScripts))
+	for i, v := range c.VerifiedScripts {
+		v.URL = rewriteURL(v.URL)
+		v.SignatureURL = rewriteURL(v.SignatureURL)
+		scripts[i] = v
+	}
+	c.VerifiedScripts = scripts
+	domains := make([]string, len(c.Domains))
+	for i, d := range c.Domains {
+		domains[i] = d
+		if m.GitHub != "" && (d == "github.com" || d == "objects.githubusercontent.com") {
+			if h := hostOf(m.GitHub); h != "" {
+				domains[i] = h
+			}
+		}
+		if m.Npm != "" && d == "registry.npmjs.org" {
+			if h := hostOf(m.Npm); h != "" {
+				domains[i] = h
+			}
+		}
+	}
+	c.Domains = dedupe(domains)
+	return c
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+func dedupe(in []string) []string {
+	seen := map[string]bool{}
+	out := in[:0]
+	for _, s := range in {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
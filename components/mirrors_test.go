@@ -0,0 +1,43 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ishida722/setup/installer"
+)
+
+func TestApplyMirrorsRewritesAllBackends(t *testing.T) {
+	m := Mirrors{
+		Apt:    "https://mirror.example.com/ubuntu",
+		Npm:    "https://proxy.example.com/npm",
+		GitHub: "https://proxy.example.com/github",
+	}
+	out := ApplyMirrors(m, []installer.InstallCommand{Neovim(), ClaudeCode()})
+
+	if len(out) != 3 || out[0].Name != "apt mirror" {
+		t.Fatalf("expected prepended apt mirror component, got %d components", len(out))
+	}
+	nvim := out[1]
+	if got := nvim.Downloads[0].URL; !strings.HasPrefix(got, "https://proxy.example.com/github/") {
+		t.Fatalf("download not rewritten: %s", got)
+	}
+	for _, d := range nvim.Domains {
+		if d == "github.com" || d == "objects.githubusercontent.com" {
+			t.Fatalf("domains not rewritten: %v", nvim.Domains)
+		}
+	}
+	claude := out[2]
+	npm := claude.InstallCommands[0]
+	if !strings.Contains(npm, "--registry=https://proxy.example.com/npm") {
+		t.Fatalf("npm registry not applied: %s", npm)
+	}
+}
+
+func TestApplyMirrorsNoOverridesIsNoop(t *testing.T) {
+	cmds := []installer.InstallCommand{Neovim()}
+	out := ApplyMirrors(Mirrors{}, cmds)
+	if len(out) != 1 || out[0].Downloads[0].URL != cmds[0].Downloads[0].URL {
+		t.Fatal("empty Mirrors changed the components")
+	}
+}
@@ -0,0 +1,40 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// miseFishActivation hooks mise into fish via conf.d; guarded so a
+// shell without mise on PATH still starts cleanly.
+const miseFishActivation = `if test -x "$HOME/.local/bin/mise"
+    "$HOME/.local/bin/mise" activate fish | source
+end
+`
+
+// Mise installs the mise runtime manager via its official install
+// script (pinned and diffed like the other verified scripts), activates
+// it in fish and bash, and installs the global tool versions declared
+// in the dotfiles (~/.tool-versions or ~/.config/mise/config.toml) —
+// an alternative to managing Node/Python/Go one component at a time.
+func Mise() installer.InstallCommand {
+	return installer.InstallCommand{
+		Name:          "mise runtime manager",
+		License:       "MIT",
+		Domains:       []string{"mise.run", "mise.jdx.dev", "github.com"},
+		CheckCommands: []string{`test -x "$HOME/.local/bin/mise"`},
+		VerifiedScripts: []installer.VerifiedScript{
+			{URL: "https://mise.run"},
+		},
+		InstallCommands: []string{
+			`mkdir -p "$HOME/.config/fish/conf.d"`,
+			fmt.Sprintf(`printf '%%s' %s > "$HOME/.config/fish/conf.d/mise.fish"`, shellQuote(miseFishActivation)),
+			`grep -qs 'mise activate bash' "$HOME/.bashrc" || echo 'eval "$("$HOME/.local/bin/mise" activate bash)"' >> "$HOME/.bashrc"`,
+			// Global tools come from the dotfiles; nothing declared is
+			// not an error.
+			`test ! -f "$HOME/.tool-versions" && test ! -f "$HOME/.config/mise/config.toml" || "$HOME/.local/bin/mise" install --yes`,
+		},
+		PostCheckCommands: []string{`"$HOME/.local/bin/mise" --version > /dev/null`},
+	}
+}
@@ -0,0 +1,20 @@
+package components
+
+import "github.com/ishida722/setup/installer"
+
+// Mosh installs the mobile shell. When the UFW component is also
+// selected, openFirewall opens mosh's UDP port range so the two stay
+// in sync — the kind of coordination neither apt nor ufw does alone.
+func Mosh(openFirewall bool) installer.InstallCommand {
+	commands := []string{
+		"sudo apt-get install -y mosh",
+	}
+	if openFirewall {
+		commands = append(commands, UFWAllow("60000:61000/udp", "mosh"))
+	}
+	return installer.InstallCommand{
+		Name:            "mosh",
+		Checks:          []installer.Check{installer.BinaryExists("mosh")},
+		InstallCommands: commands,
+	}
+}
@@ -0,0 +1,46 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// StatusFile is where `setup status` records its last result so the
+// MOTD (and other consumers) can show it without re-running checks.
+const StatusFile = "/var/lib/setup/last-status"
+
+// motdScript is the dynamic MOTD fragment showing setup-managed
+// component versions and the last `setup status` result.
+const motdScript = `#!/bin/sh
+# Installed by setup (github.com/ishida722/setup).
+echo
+echo "setup-managed components:"
+for c in node nvim fish yazi lazygit gh; do
+    command -v "$c" > /dev/null 2>&1 &&
+        printf '  %-8s %s\n' "$c" "$("$c" --version 2> /dev/null | head -1)"
+done
+if [ -f ` + StatusFile + ` ]; then
+    echo "last setup status: $(cat ` + StatusFile + `)"
+fi
+`
+
+// MOTD disables Ubuntu's promotional MOTD parts and installs a dynamic
+// fragment showing component versions and the last status result at
+// login.
+func MOTD() installer.InstallCommand {
+	const path = "/etc/update-motd.d/60-setup"
+	return installer.InstallCommand{
+		Name: "MOTD",
+		CheckCommands: []string{
+			fmt.Sprintf("cmp -s %s <(printf '%%s' %s)", path, shellQuote(motdScript)),
+		},
+		InstallCommands: []string{
+			// Silence the ad-laden parts without deleting the files.
+			"for f in /etc/update-motd.d/10-help-text /etc/update-motd.d/50-motd-news /etc/update-motd.d/80-esm /etc/update-motd.d/95-hwe-eol; do test -f \"$f\" && sudo chmod -x \"$f\" || true; done",
+			"test -f /etc/default/motd-news && sudo sed -i 's/^ENABLED=1/ENABLED=0/' /etc/default/motd-news || true",
+			fmt.Sprintf("printf '%%s' %s | sudo tee %s > /dev/null", shellQuote(motdScript), path),
+			"sudo chmod +x " + path,
+		},
+	}
+}
@@ -0,0 +1,92 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// Mount declares one network share, mirroring an fstab entry.
+type Mount struct {
+	// Source is the remote share, e.g. "server:/export" for NFS or
+	// "//server/share" for CIFS.
+	Source string
+	// Point is the local mount point; created if missing.
+	Point string
+	// Type is "nfs", "nfs4", or "cifs".
+	Type string
+	// Options are the fstab options. For CIFS, point credentials at a
+	// root-readable file with "credentials=/etc/cifs-creds" rather
+	// than embedding a password here — the line ends up in /etc/fstab.
+	Options string
+}
+
+// ParseMounts parses semicolon-separated fstab-style entries, each
+// "source mountpoint type [options]", e.g.
+// "server:/export /mnt/data nfs;//server/share /mnt/share cifs credentials=/etc/cifs-creds".
+func ParseMounts(spec string) ([]Mount, error) {
+	var mounts []Mount
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		fields := strings.Fields(entry)
+		if len(fields) < 3 || len(fields) > 4 {
+			return nil, fmt.Errorf("mount entry %q: want \"source mountpoint type [options]\"", entry)
+		}
+		m := Mount{Source: fields[0], Point: fields[1], Type: fields[2]}
+		if len(fields) == 4 {
+			m.Options = fields[3]
+		}
+		switch m.Type {
+		case "nfs", "nfs4", "cifs":
+		default:
+			return nil, fmt.Errorf("mount entry %q: unsupported type %q", entry, m.Type)
+		}
+		mounts = append(mounts, m)
+	}
+	return mounts, nil
+}
+
+// NetworkMounts installs the client packages the declared shares need,
+// creates the mount points, appends the missing fstab entries, and
+// mounts everything. Entries already in fstab are left untouched, so
+// local edits survive reruns.
+func NetworkMounts(mounts []Mount) installer.InstallCommand {
+	packages := map[string]bool{}
+	var checks, commands []string
+	for _, m := range mounts {
+		if m.Type == "cifs" {
+			packages["cifs-utils"] = true
+		} else {
+			packages["nfs-common"] = true
+		}
+	}
+	for _, pkg := range []string{"nfs-common", "cifs-utils"} {
+		if packages[pkg] {
+			commands = append(commands, "sudo apt-get install -y "+pkg)
+		}
+	}
+	for _, m := range mounts {
+		opts := m.Options
+		if opts == "" {
+			// _netdev defers mounting until the network is up.
+			opts = "defaults,_netdev"
+		}
+		line := fmt.Sprintf("%s %s %s %s 0 0", m.Source, m.Point, m.Type, opts)
+		checks = append(checks, fmt.Sprintf("findmnt -rn %s > /dev/null", m.Point))
+		commands = append(commands,
+			fmt.Sprintf("sudo mkdir -p %s", m.Point),
+			fmt.Sprintf(`grep -qsE "^\S+ %s " /etc/fstab || echo %s | sudo tee -a /etc/fstab > /dev/null`,
+				m.Point, shellQuote(line)),
+			fmt.Sprintf("findmnt -rn %s > /dev/null || sudo mount %s", m.Point, m.Point),
+		)
+	}
+	return installer.InstallCommand{
+		Name:            "network mounts",
+		CheckCommands:   checks,
+		InstallCommands: commands,
+	}
+}
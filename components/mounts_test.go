@@ -0,0 +1,23 @@
+package components
+
+import "testing"
+
+func TestParseMounts(t *testing.T) {
+	mounts, err := ParseMounts("server:/export /mnt/data nfs;//server/share /mnt/share cifs credentials=/etc/cifs-creds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounts) != 2 {
+		t.Fatalf("got %d mounts", len(mounts))
+	}
+	if mounts[0].Options != "" || mounts[1].Options != "credentials=/etc/cifs-creds" {
+		t.Fatalf("options wrong: %+v", mounts)
+	}
+
+	if _, err := ParseMounts("server:/export /mnt/data ext4"); err == nil {
+		t.Fatal("unsupported type accepted")
+	}
+	if _, err := ParseMounts("server:/export"); err == nil {
+		t.Fatal("short entry accepted")
+	}
+}
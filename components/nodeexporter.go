@@ -0,0 +1,44 @@
+package components
+
+import (
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/systemdunit"
+)
+
+// NodeExporter installs Prometheus node_exporter from the latest
+// GitHub release, registers its systemd service, and (when the UFW
+// component is also selected) opens the metrics port, so new hosts
+// appear in monitoring without a separate Ansible pass.
+func NodeExporter(openFirewall bool) installer.InstallCommand {
+	unit := systemdunit.Unit{
+		Name: "node_exporter.service",
+		Content: `[Unit]
+Description=Prometheus node_exporter
+After=network-online.target
+
+[Service]
+User=node_exporter
+Group=node_exporter
+ExecStart=/usr/local/bin/node_exporter
+Restart=on-failure
+
+[Install]
+WantedBy=multi-user.target
+`,
+		Enable: true,
+	}
+	commands := []string{
+		`arch=$(uname -m); case "$arch" in x86_64) target=amd64 ;; aarch64) target=arm64 ;; *) echo "unsupported architecture: $arch" >&2; exit 1 ;; esac && version=$(curl -fsSL https://api.github.com/repos/prometheus/node_exporter/releases/latest | grep -Po '"tag_name": *"v\K[^"]*') && curl -fsSL -o /tmp/node_exporter.tar.gz "https://github.com/prometheus/node_exporter/releases/download/v${version}/node_exporter-${version}.linux-${target}.tar.gz" && tar -C /tmp -xzf /tmp/node_exporter.tar.gz "node_exporter-${version}.linux-${target}/node_exporter" && sudo install "/tmp/node_exporter-${version}.linux-${target}/node_exporter" /usr/local/bin`,
+		"id -u node_exporter > /dev/null 2>&1 || sudo useradd --system --no-create-home --shell /usr/sbin/nologin node_exporter",
+	}
+	unitCmd := unit.InstallCommand()
+	commands = append(commands, unitCmd.InstallCommands...)
+	if openFirewall {
+		commands = append(commands, UFWAllow("9100/tcp", "node_exporter"))
+	}
+	return installer.InstallCommand{
+		Name:            "node_exporter",
+		Checks:          []installer.Check{installer.BinaryExists("node_exporter")},
+		InstallCommands: commands,
+	}
+}
@@ -0,0 +1,152 @@
+package components
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/shellwords"
+)
+
+// BatchNpmGlobals merges components that each install a single global
+// npm package into one `npm install -g a b c` invocation — npm's
+// startup overhead dominates for small packages. The merged step still
+// attributes failures per package by re-running each component's
+// checks afterwards. Lists with fewer than two npm components are
+// returned unchanged.
+func BatchNpmGlobals(cmds []installer.InstallCommand) []installer.InstallCommand {
+	var npm []installer.InstallCommand
+	var pkgs []string
+	first := -1
+	for i, c := range cmds {
+		if pkg, ok := npmGlobalPackage(c); ok {
+			if first < 0 {
+				first = i
+			}
+			npm = append(npm, c)
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	if len(npm) < 2 {
+		return cmds
+	}
+
+	merged := installer.InstallCommand{
+		Name:            "npm globals (" + strings.Join(pkgs, ", ") + ")",
+		DependsOn:       unionDependsOn(npm),
+		Domains:         unionDomains(npm),
+		CheckCommands:   unionCheckCommands(npm),
+		Checks:          unionChecks(npm),
+		InstallCommands: []string{"sudo npm install -g " + strings.Join(pkgs, " ")},
+		InstallFunc:     func() error { return verifyNpmGlobals(npm, pkgs) },
+	}
+
+	out := make([]installer.InstallCommand, 0, len(cmds)-len(npm)+1)
+	for i, c := range cmds {
+		if i == first {
+			out = append(out, merged)
+			continue
+		}
+		if _, ok := npmGlobalPackage(c); ok {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// npmGlobalPackage recognizes a component whose whole install is one
+// global npm package and returns that package.
+func npmGlobalPackage(c installer.InstallCommand) (string, bool) {
+	if len(c.InstallCommands) != 1 || c.InstallFunc != nil {
+		return "", false
+	}
+	words, err := shellwords.Split(c.InstallCommands[0])
+	if err != nil {
+		return "", false
+	}
+	if len(words) > 0 && words[0] == "sudo" {
+		words = words[1:]
+	}
+	if len(words) != 4 || words[0] != "npm" || words[1] != "install" {
+		return "", false
+	}
+	if words[2] != "-g" && words[2] != "--global" {
+		return "", false
+	}
+	return words[3], true
+}
+
+// verifyNpmGlobals re-runs each merged component's checks so a partial
+// npm failure names the packages that did not land.
+func verifyNpmGlobals(npm []installer.InstallCommand, pkgs []string) error {
+	var failed []string
+	for i, c := range npm {
+		for _, chk := range c.Checks {
+			if !chk.OK() {
+				failed = append(failed, pkgs[i])
+				break
+			}
+		}
+		if len(failed) > 0 && failed[len(failed)-1] == pkgs[i] {
+			continue
+		}
+		for _, check := range c.CheckCommands {
+			if installer.RunTo(check, io.Discard, io.Discard) != nil {
+				failed = append(failed, pkgs[i])
+				break
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("npm packages failed to install: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func unionDependsOn(cmds []installer.InstallCommand) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, c := range cmds {
+		for _, d := range c.DependsOn {
+			if !seen[d] {
+				seen[d] = true
+				out = append(out, d)
+			}
+		}
+	}
+	return out
+}
+
+func unionDomains(cmds []installer.InstallCommand) []string {
+	seen := map[string]bool{}
+	for _, c := range cmds {
+		for _, d := range c.Domains {
+			seen[d] = true
+		}
+	}
+	out := make([]string, 0, len(seen))
+	for d := range seen {
+		out = append(out, d)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func unionCheckCommands(cmds []installer.InstallCommand) []string {
+	var out []string
+	for _, c := range cmds {
+		out = append(out, c.CheckCommands...)
+	}
+	return out
+}
+
+func unionChecks(cmds []installer.InstallCommand) []installer.Check {
+	var out []installer.Check
+	for _, c := range cmds {
+		out = append(out, c.Checks...)
+	}
+	return out
+}
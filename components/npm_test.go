@@ -0,0 +1,40 @@
+package components_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ishida722/setup/components"
+	"github.com/ishida722/setup/installer"
+)
+
+func TestBatchNpmGlobalsMergesIntoOneInvocation(t *testing.T) {
+	cmds := []installer.InstallCommand{
+		{Name: "base", InstallCommands: []string{"sudo apt-get update"}},
+		{Name: "a", CheckCommands: []string{"command -v a"}, InstallCommands: []string{"sudo npm install -g a-cli"}},
+		{Name: "b", CheckCommands: []string{"command -v b"}, InstallCommands: []string{"sudo npm install -g @scope/b"}},
+	}
+	out := components.BatchNpmGlobals(cmds)
+	if len(out) != 2 {
+		t.Fatalf("got %d components, want 2: %+v", len(out), out)
+	}
+	merged := out[1]
+	want := "sudo npm install -g a-cli @scope/b"
+	if len(merged.InstallCommands) != 1 || merged.InstallCommands[0] != want {
+		t.Errorf("install commands = %v, want [%q]", merged.InstallCommands, want)
+	}
+	if len(merged.CheckCommands) != 2 {
+		t.Errorf("check commands = %v, want both originals", merged.CheckCommands)
+	}
+	if !strings.Contains(merged.Name, "a-cli") || !strings.Contains(merged.Name, "@scope/b") {
+		t.Errorf("merged name %q does not list the packages", merged.Name)
+	}
+}
+
+func TestBatchNpmGlobalsLeavesSinglePackageAlone(t *testing.T) {
+	cmds := []installer.InstallCommand{components.ClaudeCode()}
+	out := components.BatchNpmGlobals(cmds)
+	if len(out) != 1 || out[0].Name != cmds[0].Name {
+		t.Errorf("single npm component was rewritten: %+v", out)
+	}
+}
@@ -0,0 +1,48 @@
+package components
+
+import (
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+)
+
+// Nvidia installs the recommended NVIDIA driver via ubuntu-drivers and
+// optionally the CUDA toolkit and cuDNN from NVIDIA's official
+// repository. The kernel module only loads at boot, so after the first
+// install the component checkpoints through the same reboot/resume
+// mechanism as SystemUpgrade; the resumed run sees nvidia-smi working
+// and skips straight past it.
+func Nvidia(cuda bool, policy string, resumeArgs []string) installer.InstallCommand {
+	name := "NVIDIA driver"
+	commands := []string{
+		// Fail early when the machine has no NVIDIA GPU at all.
+		"lspci | grep -qi nvidia",
+		"sudo apt-get install -y ubuntu-drivers-common",
+		"sudo ubuntu-drivers install",
+	}
+	var domains []string
+	if cuda {
+		name = "NVIDIA driver + CUDA toolkit"
+		domains = []string{"developer.download.nvidia.com"}
+		commands = append(commands,
+			// cuda-keyring registers NVIDIA's repo for this Ubuntu
+			// release; the toolkit and cuDNN come from there.
+			`distro=ubuntu$(. /etc/os-release && echo $VERSION_ID | tr -d .) && curl -fsSL -o /tmp/cuda-keyring.deb https://developer.download.nvidia.com/compute/cuda/repos/$distro/x86_64/cuda-keyring_1.1-1_all.deb`,
+			"sudo dpkg -i /tmp/cuda-keyring.deb",
+			"sudo apt-get update",
+			"sudo apt-get install -y cuda-toolkit cudnn",
+		)
+	}
+	return installer.InstallCommand{
+		Name:            name,
+		CheckCommands:   []string{"nvidia-smi > /dev/null"},
+		Domains:         domains,
+		InstallCommands: commands,
+		InstallFunc: func() error {
+			if err := installer.Run("nvidia-smi > /dev/null 2>&1"); err == nil {
+				return nil
+			}
+			logger.Info("the NVIDIA driver loads at boot; a reboot is required")
+			return applyRebootPolicy(policy, resumeArgs)
+		},
+	}
+}
@@ -0,0 +1,38 @@
+package components
+
+import "github.com/ishida722/setup/installer"
+
+// PipeWire ensures PipeWire with WirePlumber handles audio (including
+// Bluetooth codecs), taking over from PulseAudio where it is still
+// running. The user services survive re-login; pipewire-pulse keeps
+// PulseAudio clients working.
+func PipeWire() installer.InstallCommand {
+	return installer.InstallCommand{
+		Name: "PipeWire audio stack",
+		CheckCommands: []string{
+			"systemctl --user is-active --quiet pipewire",
+			"systemctl --user is-active --quiet wireplumber",
+		},
+		InstallCommands: []string{
+			"sudo apt-get install -y pipewire pipewire-pulse wireplumber libspa-0.2-bluetooth",
+			"systemctl --user disable --now pulseaudio.service pulseaudio.socket 2> /dev/null || true",
+			"systemctl --user mask pulseaudio 2> /dev/null || true",
+			"systemctl --user enable --now pipewire pipewire-pulse wireplumber",
+		},
+		PostCheckCommands: []string{"pactl info | grep -q PipeWire"},
+	}
+}
+
+// Bluetooth installs BlueZ, powers the adapter on at boot via
+// AutoEnable, and starts the service.
+func Bluetooth() installer.InstallCommand {
+	return installer.InstallCommand{
+		Name:          "Bluetooth",
+		CheckCommands: []string{"systemctl is-active --quiet bluetooth"},
+		InstallCommands: []string{
+			"sudo apt-get install -y bluez",
+			`sudo sed -i 's/^#\?AutoEnable=.*/AutoEnable=true/' /etc/bluetooth/main.conf`,
+			"sudo systemctl enable --now bluetooth",
+		},
+	}
+}
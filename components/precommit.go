@@ -0,0 +1,70 @@
+package components
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+)
+
+// PreCommit installs pre-commit and activates its hooks in every given
+// work repository that declares a .pre-commit-config.yaml, so a fresh
+// machine enforces the same checks as CI from the first commit. Dirs
+// may be relative to $HOME and may contain globs; nil means ~/src/*.
+func PreCommit(dirs []string) installer.InstallCommand {
+	if len(dirs) == 0 {
+		dirs = []string{"src/*"}
+	}
+	// Per-repo checks so a newly cloned repository re-runs the hook
+	// installation even though pre-commit itself is already present.
+	checks := []string{"command -v pre-commit > /dev/null"}
+	for _, repo := range expandRepoDirs(dirs) {
+		if _, err := os.Stat(filepath.Join(repo, ".pre-commit-config.yaml")); err == nil {
+			checks = append(checks, fmt.Sprintf("test -x %q", filepath.Join(repo, ".git", "hooks", "pre-commit")))
+		}
+	}
+	return installer.InstallCommand{
+		Name:          "pre-commit hooks",
+		CheckCommands: checks,
+		InstallCommands: []string{
+			"sudo apt-get install -y pre-commit",
+		},
+		InstallFunc: func() error {
+			for _, repo := range expandRepoDirs(dirs) {
+				if _, err := os.Stat(filepath.Join(repo, ".pre-commit-config.yaml")); err != nil {
+					continue
+				}
+				if err := installer.RunCmd(installer.Cmd{Command: "pre-commit install --install-hooks", Dir: repo}); err != nil {
+					return fmt.Errorf("install hooks in %s: %w", repo, err)
+				}
+				logger.Debug("pre-commit hooks installed in %s", repo)
+			}
+			return nil
+		},
+	}
+}
+
+// expandRepoDirs resolves the directory patterns against $HOME and
+// keeps only directories that are git repositories.
+func expandRepoDirs(patterns []string) []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	var repos []string
+	for _, pattern := range patterns {
+		if !strings.HasPrefix(pattern, "/") {
+			pattern = filepath.Join(home, pattern)
+		}
+		matches, _ := filepath.Glob(pattern)
+		for _, dir := range matches {
+			if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+				repos = append(repos, dir)
+			}
+		}
+	}
+	return repos
+}
@@ -0,0 +1,19 @@
+#!/bin/bash
+# Installs the latest Lazygit release binary. Run by the setup binary
+# from an embedded copy; keep it standalone and idempotent.
+set -euo pipefail
+
+case "$(uname -m)" in
+    x86_64) arch=Linux_x86_64 ;;
+    aarch64) arch=Linux_arm64 ;;
+    *)
+        echo "unsupported architecture: $(uname -m)" >&2
+        exit 1
+        ;;
+esac
+version=$(curl -fsSL https://api.github.com/repos/jesseduffield/lazygit/releases/latest |
+    grep -Po '"tag_name": *"v\K[^"]*')
+curl -fsSL -o /tmp/lazygit.tar.gz \
+    "https://github.com/jesseduffield/lazygit/releases/download/v${version}/lazygit_${version}_${arch}.tar.gz"
+tar -C /tmp -xzf /tmp/lazygit.tar.gz lazygit
+sudo install /tmp/lazygit /usr/local/bin
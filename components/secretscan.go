@@ -0,0 +1,94 @@
+package components
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// SecretScanBlock makes leaked-credential findings in cloned config
+// repositories fail the component instead of only warning; dotfile
+// repos are a common accidental leak vector and the clone is the
+// natural checkpoint.
+var SecretScanBlock bool
+
+// secretPatterns are the obvious credential shapes worth flagging.
+// They favour precision over recall: a warning someone ignores because
+// it fires on every machine is worse than a narrow one.
+var secretPatterns = []struct {
+	Kind string
+	Re   *regexp.Regexp
+}{
+	{"AWS access key", regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[baprs]-[A-Za-z0-9-]{10,}\b`)},
+	{"private key", regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH )?PRIVATE KEY-----`)},
+}
+
+// SecretFinding is one suspected credential in a scanned repository.
+type SecretFinding struct {
+	Path string
+	Line int
+	Kind string
+}
+
+func (f SecretFinding) String() string {
+	return fmt.Sprintf("%s:%d: looks like a %s", f.Path, f.Line, f.Kind)
+}
+
+// secretScanMaxSize skips binaries and large archives; credentials
+// leak through text files.
+const secretScanMaxSize = 1 << 20
+
+// ScanSecrets walks the directory (skipping .git) and reports lines
+// matching the credential patterns.
+func ScanSecrets(dir string) ([]SecretFinding, error) {
+	var findings []SecretFinding
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Size() > secretScanMaxSize {
+			return nil
+		}
+		found, err := scanFileForSecrets(path)
+		findings = append(findings, found...)
+		return err
+	})
+	return findings, err
+}
+
+// scanFileForSecrets scans one file line by line.
+func scanFileForSecrets(path string) ([]SecretFinding, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var findings []SecretFinding
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), secretScanMaxSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		for _, p := range secretPatterns {
+			if p.Re.MatchString(text) {
+				findings = append(findings, SecretFinding{Path: path, Line: line, Kind: p.Kind})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil && !strings.Contains(err.Error(), "token too long") {
+		return findings, err
+	}
+	return findings, nil
+}
@@ -0,0 +1,38 @@
+package components
+
+import (
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+)
+
+// PasswordlessSudo grants the provisioning user NOPASSWD sudo via a
+// sudoers.d drop-in. It is off by default and asks for confirmation
+// (skipped with assumeYes) because it weakens the machine — intended
+// for lab VMs and CI machines only. The drop-in is validated with
+// visudo -c before it is installed.
+func PasswordlessSudo(assumeYes bool) installer.InstallCommand {
+	const path = "/etc/sudoers.d/90-setup-nopasswd"
+	return installer.InstallCommand{
+		Name: "passwordless sudo",
+		CheckCommands: []string{
+			`sudo grep -qx "$USER ALL=(ALL) NOPASSWD:ALL" ` + path + " 2> /dev/null",
+		},
+		InstallFunc: func() error {
+			if !assumeYes && !confirm("Grant passwordless sudo to $USER?") {
+				logger.Info("passwordless sudo declined, skipping")
+				return nil
+			}
+			for _, cmd := range []string{
+				`printf '%s ALL=(ALL) NOPASSWD:ALL\n' "$USER" > /tmp/setup-nopasswd`,
+				"sudo visudo -c -f /tmp/setup-nopasswd",
+				"sudo install -m 0440 /tmp/setup-nopasswd " + path,
+				"rm -f /tmp/setup-nopasswd",
+			} {
+				if err := installer.Run(cmd); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
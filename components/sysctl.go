@@ -0,0 +1,68 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// sysctlProfiles are the declarative value sets the sysctl component
+// can apply.
+var sysctlProfiles = map[string]map[string]string{
+	// server: networking tweaks for machines serving traffic.
+	"server": {
+		"fs.inotify.max_user_watches":  "524288",
+		"net.core.somaxconn":           "1024",
+		"net.ipv4.tcp_max_syn_backlog": "4096",
+		"vm.swappiness":                "10",
+	},
+	// desktop: defaults for an interactive development machine.
+	"desktop": {
+		"fs.inotify.max_user_watches": "524288",
+		"vm.swappiness":               "10",
+	},
+}
+
+// SysctlProfiles lists the available profile names.
+func SysctlProfiles() []string {
+	names := make([]string, 0, len(sysctlProfiles))
+	for name := range sysctlProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Sysctl writes the profile's values to /etc/sysctl.d and applies them
+// immediately. The checks compare the running kernel values, so
+// `setup status` reports drift even if the file is still in place.
+func Sysctl(profile string) (installer.InstallCommand, error) {
+	values, ok := sysctlProfiles[profile]
+	if !ok {
+		return installer.InstallCommand{}, fmt.Errorf("unknown sysctl profile %q (available: %s)",
+			profile, strings.Join(SysctlProfiles(), ", "))
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var conf strings.Builder
+	checks := make([]string, 0, len(keys))
+	for _, k := range keys {
+		fmt.Fprintf(&conf, "%s = %s\n", k, values[k])
+		checks = append(checks, fmt.Sprintf(`test "$(sysctl -n %s)" = %q`, k, values[k]))
+	}
+	const path = "/etc/sysctl.d/99-setup.conf"
+	return installer.InstallCommand{
+		Name:          "sysctl profile " + profile,
+		CheckCommands: checks,
+		InstallCommands: []string{
+			fmt.Sprintf("printf '%%s' %s | sudo tee %s > /dev/null", shellQuote(conf.String()), path),
+			"sudo sysctl -p " + path,
+		},
+	}, nil
+}
@@ -0,0 +1,72 @@
+package components
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// Terraform installs a pinned Terraform release from
+// releases.hashicorp.com, verified against the published SHA256SUMS.
+// Each version lands in its own /opt/terraform-<version> tree with
+// /usr/local/bin/terraform pointing at the selected one, so switching
+// versions is just a re-run with a different -terraform; superseded
+// trees are reclaimed by `setup gc`.
+func Terraform(version string) installer.InstallCommand {
+	zip := fmt.Sprintf("terraform_%s_linux_%s.zip", version, runtime.GOARCH)
+	base := fmt.Sprintf("https://releases.hashicorp.com/terraform/%s/", version)
+	sums := fmt.Sprintf("terraform_%s_SHA256SUMS", version)
+	return installer.InstallCommand{
+		Name:           "Terraform",
+		License:        "BUSL-1.1",
+		Domains:        []string{"releases.hashicorp.com"},
+		VersionCommand: "terraform version",
+		Binaries:       []string{"/usr/local/bin/terraform"},
+		CheckCommands: []string{
+			// Version-sensitive: a different pin reinstalls.
+			fmt.Sprintf(`terraform version | grep -q "Terraform v%s"`, version),
+		},
+		Downloads: []installer.Download{
+			{URL: base + zip, Dest: "/tmp/" + zip},
+		},
+		InstallCommands: []string{
+			fmt.Sprintf("test -s /tmp/%s || curl -fsSL -o /tmp/%s %q", zip, zip, base+zip),
+			fmt.Sprintf("curl -fsSL -o /tmp/%s %q", sums, base+sums),
+			fmt.Sprintf(`cd /tmp && grep " %s$" %s | sha256sum -c -`, zip, sums),
+			fmt.Sprintf("sudo mkdir -p /opt/terraform-%s", version),
+			fmt.Sprintf("sudo unzip -o /tmp/%s -d /opt/terraform-%s", zip, version),
+			fmt.Sprintf("sudo ln -sf /opt/terraform-%s/terraform /usr/local/bin/terraform", version),
+		},
+	}
+}
+
+// OpenTofu installs a pinned OpenTofu release from GitHub, verified the
+// same way as Terraform, with the same per-version /opt layout.
+func OpenTofu(version string) installer.InstallCommand {
+	zip := fmt.Sprintf("tofu_%s_linux_%s.zip", version, runtime.GOARCH)
+	base := fmt.Sprintf("https://github.com/opentofu/opentofu/releases/download/v%s/", version)
+	sums := fmt.Sprintf("tofu_%s_SHA256SUMS", version)
+	return installer.InstallCommand{
+		Name:           "OpenTofu",
+		License:        "MPL-2.0",
+		Domains:        []string{"github.com", "objects.githubusercontent.com"},
+		VersionCommand: "tofu version",
+		LatestVersion:  githubLatestTag("opentofu/opentofu"),
+		Binaries:       []string{"/usr/local/bin/tofu"},
+		CheckCommands: []string{
+			fmt.Sprintf(`tofu version | grep -q "OpenTofu v%s"`, version),
+		},
+		Downloads: []installer.Download{
+			{URL: base + zip, Dest: "/tmp/" + zip},
+		},
+		InstallCommands: []string{
+			fmt.Sprintf("test -s /tmp/%s || curl -fsSL -o /tmp/%s %q", zip, zip, base+zip),
+			fmt.Sprintf("curl -fsSL -o /tmp/%s %q", sums, base+sums),
+			fmt.Sprintf(`cd /tmp && grep " %s$" %s | sha256sum -c -`, zip, sums),
+			fmt.Sprintf("sudo mkdir -p /opt/tofu-%s", version),
+			fmt.Sprintf("sudo unzip -o /tmp/%s -d /opt/tofu-%s", zip, version),
+			fmt.Sprintf("sudo ln -sf /opt/tofu-%s/tofu /usr/local/bin/tofu", version),
+		},
+	}
+}
@@ -0,0 +1,36 @@
+package components
+
+import (
+	"path/filepath"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// hasBattery reports whether the machine has a battery, which is what
+// separates laptops from desktops and servers here.
+func hasBattery() bool {
+	matches, _ := filepath.Glob("/sys/class/power_supply/BAT*")
+	return len(matches) > 0
+}
+
+// TLP installs TLP for laptop power management and enables it; its
+// stock configuration is already tuned, so nothing is overridden.
+// Machines without a battery get a no-op, so the flag can sit in a
+// shared invocation without hurting desktops.
+func TLP() installer.InstallCommand {
+	if !hasBattery() {
+		return installer.InstallCommand{
+			Name:          "TLP power management (no battery, nothing to do)",
+			CheckCommands: []string{"true"},
+		}
+	}
+	return installer.InstallCommand{
+		Name:          "TLP power management",
+		CheckCommands: []string{"systemctl is-active --quiet tlp"},
+		InstallCommands: []string{
+			"sudo apt-get install -y tlp tlp-rdw",
+			"sudo systemctl enable --now tlp",
+		},
+		PostCheckCommands: []string{"sudo tlp-stat -s > /dev/null"},
+	}
+}
@@ -0,0 +1,27 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// UFW enables the uncomplicated firewall with SSH allowed, so exposing
+// a fresh VPS does not race against the rest of the setup.
+func UFW() installer.InstallCommand {
+	return installer.InstallCommand{
+		Name:          "UFW firewall",
+		CheckCommands: []string{`sudo ufw status | grep -q "Status: active"`},
+		InstallCommands: []string{
+			"sudo apt-get install -y ufw",
+			"sudo ufw allow OpenSSH",
+			"sudo ufw --force enable",
+		},
+	}
+}
+
+// UFWAllow opens one port specification (e.g. "60000:61000/udp") with a
+// comment naming the component that needs it.
+func UFWAllow(spec, comment string) string {
+	return fmt.Sprintf("sudo ufw allow %s comment %q", spec, comment)
+}
@@ -0,0 +1,108 @@
+package components
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+	"github.com/ishida722/setup/systemdunit"
+)
+
+// Reboot policies for SystemUpgrade.
+const (
+	RebootAsk  = "ask"  // prompt before rebooting
+	RebootAuto = "auto" // reboot without asking
+	RebootSkip = "skip" // only warn that a reboot is needed
+)
+
+// SystemUpgrade runs a full apt upgrade as the first step of a run.
+// If the upgrade leaves /var/run/reboot-required behind, it installs a
+// one-shot setup-resume unit that re-runs `setup install resumeArgs`
+// on the next boot and then reboots, according to the policy.
+func SystemUpgrade(policy string, resumeArgs []string) installer.InstallCommand {
+	return installer.InstallCommand{
+		Name: "system upgrade",
+		InstallCommands: []string{
+			"sudo apt-get update",
+			"sudo DEBIAN_FRONTEND=noninteractive apt-get dist-upgrade -y",
+			"sudo apt-get autoremove -y",
+		},
+		InstallFunc: func() error {
+			if _, err := os.Stat("/var/run/reboot-required"); err != nil {
+				return nil
+			}
+			logger.Info("a reboot is required to finish the system upgrade")
+			return applyRebootPolicy(policy, resumeArgs)
+		},
+	}
+}
+
+// applyRebootPolicy reboots and resumes according to the policy, or
+// leaves the reboot to the user.
+func applyRebootPolicy(policy string, resumeArgs []string) error {
+	switch policy {
+	case RebootSkip:
+		logger.Info("skipping reboot (-reboot=skip); reboot manually when convenient")
+		return nil
+	case RebootAsk:
+		if !confirm("Reboot now and resume setup afterwards?") {
+			logger.Info("reboot declined; continuing without rebooting")
+			return nil
+		}
+	case RebootAuto:
+	default:
+		return fmt.Errorf("unknown reboot policy %q", policy)
+	}
+	return rebootAndResume(resumeArgs)
+}
+
+// rebootAndResume registers a one-shot unit that re-runs setup after
+// the reboot (every component is idempotent, so the finished steps are
+// skipped) and reboots the machine.
+func rebootAndResume(resumeArgs []string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate setup binary: %w", err)
+	}
+	exec := self + " install"
+	for _, a := range resumeArgs {
+		exec += " " + a
+	}
+	unit := systemdunit.Unit{
+		Name: "setup-resume.service",
+		Content: fmt.Sprintf(`[Unit]
+Description=Resume setup after reboot
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=oneshot
+ExecStart=%s
+ExecStartPost=/usr/bin/systemctl disable setup-resume.service
+
+[Install]
+WantedBy=multi-user.target
+`, exec),
+	}
+	cmd := unit.InstallCommand()
+	cmd.InstallCommands = append(cmd.InstallCommands, "sudo systemctl enable setup-resume.service")
+	if err := cmd.Install(); err != nil {
+		return err
+	}
+	logger.Info("rebooting; setup will resume on next boot")
+	return installer.Run("sudo systemctl reboot")
+}
+
+// confirm asks a yes/no question on the terminal.
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N]: ", prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,43 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// VSCode installs Visual Studio Code from Microsoft's apt repository,
+// installs the declared extensions, and drops a settings.json into the
+// user profile. Extensions already present are skipped by their own
+// checks, so adding one to the list is an incremental re-run.
+func VSCode(extensions []string, settings string) installer.InstallCommand {
+	checks := []installer.Check{installer.BinaryExists("code")}
+	var checkCommands []string
+	commands := []string{
+		"curl -fsSL https://packages.microsoft.com/keys/microsoft.asc | sudo gpg --dearmor -o /usr/share/keyrings/packages.microsoft.gpg --yes",
+		`echo "deb [arch=amd64,arm64 signed-by=/usr/share/keyrings/packages.microsoft.gpg] https://packages.microsoft.com/repos/code stable main" | sudo tee /etc/apt/sources.list.d/vscode.list > /dev/null`,
+		"sudo apt-get update",
+		"sudo apt-get install -y code",
+	}
+	for _, ext := range extensions {
+		checkCmd := fmt.Sprintf("code --list-extensions | grep -qix %q", ext)
+		// Per-extension checks so a newly declared extension re-runs
+		// the (idempotent) install even when code itself is present.
+		checkCommands = append(checkCommands, checkCmd)
+		commands = append(commands, fmt.Sprintf("%s || code --install-extension %q", checkCmd, ext))
+	}
+	if settings != "" {
+		commands = append(commands,
+			fmt.Sprintf(`install -D -m 644 %q "$HOME/.config/Code/User/settings.json"`, settings))
+	}
+	return installer.InstallCommand{
+		Name:            "Visual Studio Code",
+		License:         "Proprietary",
+		Domains:         []string{"packages.microsoft.com"},
+		VersionCommand:  "code --version",
+		LatestVersion:   aptCandidate("code"),
+		Checks:          checks,
+		CheckCommands:   checkCommands,
+		InstallCommands: commands,
+	}
+}
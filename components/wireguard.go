@@ -0,0 +1,82 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// WireGuardConfig describes the wg0 interface for WireGuard.
+type WireGuardConfig struct {
+	// Address of this host inside the VPN, e.g. "10.8.0.2/24".
+	Address string
+	// PrivateKeyFile is an existing private key to install; when
+	// empty a key is generated on the machine and never leaves it.
+	PrivateKeyFile string
+	// PeerPublicKey and PeerEndpoint identify the server to connect
+	// to.
+	PeerPublicKey string
+	PeerEndpoint  string
+	// AllowedIPs routed through the peer; defaults to the VPN subnet
+	// side of Address being untouched — set "0.0.0.0/0" for a full
+	// tunnel.
+	AllowedIPs string
+	// ListenPort defaults to 51820.
+	ListenPort int
+}
+
+// WireGuard installs wireguard-tools, renders /etc/wireguard/wg0.conf
+// from the config with the key read at render time (it never passes
+// through this process), enables wg-quick@wg0, and opens the firewall
+// port when UFW is selected.
+func WireGuard(cfg WireGuardConfig, openFirewall bool) installer.InstallCommand {
+	if cfg.ListenPort == 0 {
+		cfg.ListenPort = 51820
+	}
+	if cfg.AllowedIPs == "" {
+		cfg.AllowedIPs = "0.0.0.0/0"
+	}
+
+	commands := []string{
+		"sudo apt-get install -y wireguard-tools",
+	}
+	if cfg.PrivateKeyFile != "" {
+		commands = append(commands,
+			fmt.Sprintf("sudo install -m 600 -o root -g root %q /etc/wireguard/wg0.key", cfg.PrivateKeyFile))
+	} else {
+		commands = append(commands,
+			// sudo for the existence probe: /etc/wireguard is 0700
+			// root, so an unprivileged test would miss the key and
+			// regenerate it, breaking the established peer.
+			"sudo test -f /etc/wireguard/wg0.key || wg genkey | sudo install -m 600 /dev/stdin /etc/wireguard/wg0.key")
+	}
+	conf := fmt.Sprintf(`[Interface]
+Address = %s
+PrivateKey = $(sudo cat /etc/wireguard/wg0.key)
+ListenPort = %d
+
+[Peer]
+PublicKey = %s
+Endpoint = %s
+AllowedIPs = %s
+PersistentKeepalive = 25
+`, cfg.Address, cfg.ListenPort, cfg.PeerPublicKey, cfg.PeerEndpoint, cfg.AllowedIPs)
+	commands = append(commands,
+		// The key is substituted by the shell at render time, so the
+		// rendered conf exists only root-readable on the target.
+		fmt.Sprintf(`printf '%%s' "%s" | sudo install -m 600 /dev/stdin /etc/wireguard/wg0.conf`, conf),
+		"sudo systemctl enable --now wg-quick@wg0",
+	)
+	if openFirewall {
+		commands = append(commands, UFWAllow(fmt.Sprintf("%d/udp", cfg.ListenPort), "wireguard"))
+	}
+	return installer.InstallCommand{
+		Name: "WireGuard (wg0)",
+		CheckCommands: []string{
+			"sudo test -f /etc/wireguard/wg0.conf",
+			"systemctl is-active --quiet wg-quick@wg0",
+		},
+		InstallCommands:   commands,
+		PostCheckCommands: []string{"sudo wg show wg0 > /dev/null"},
+	}
+}
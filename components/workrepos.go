@@ -0,0 +1,126 @@
+package components
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+)
+
+// WorkRepo is one repository to have ready after provisioning.
+type WorkRepo struct {
+	URL string
+	// Dest is relative to $HOME; empty means src/<repo name>.
+	Dest string
+	// Branch to check out; empty means the remote default.
+	Branch string
+}
+
+// ParseWorkRepos parses semicolon-separated "url [dest] [branch]"
+// entries, e.g. "https://github.com/org/app;https://github.com/org/lib src/lib main".
+func ParseWorkRepos(spec string) ([]WorkRepo, error) {
+	var repos []WorkRepo
+	for _, entry := range strings.Split(spec, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > 3 {
+			return nil, fmt.Errorf("repo entry %q: want \"url [dest] [branch]\"", entry)
+		}
+		repo := WorkRepo{URL: fields[0]}
+		if len(fields) > 1 {
+			repo.Dest = fields[1]
+		}
+		if len(fields) > 2 {
+			repo.Branch = fields[2]
+		}
+		if repo.Dest == "" {
+			repo.Dest = filepath.Join("src", strings.TrimSuffix(filepath.Base(repo.URL), ".git"))
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
+
+// Auth choices for WorkRepos.
+const (
+	RepoAuthHTTPS = "https" // clone URLs as declared
+	RepoAuthSSH   = "ssh"   // rewrite github.com URLs to git@ form
+	RepoAuthGh    = "gh"    // clone through the authenticated gh CLI
+)
+
+// WorkRepos clones the declared repositories in parallel (reusing the
+// config-repo concurrency bound) and fast-forwards the ones that
+// already exist, so a provisioned machine is ready to work on without
+// a round of manual clones.
+func WorkRepos(repos []WorkRepo, auth string) installer.InstallCommand {
+	return installer.InstallCommand{
+		Name:        "work repositories",
+		Domains:     []string{"github.com"},
+		InstallFunc: func() error { return syncWorkRepos(repos, auth) },
+	}
+}
+
+// syncWorkRepos clones or updates every repository, aggregating the
+// per-repo outcomes into one error like cloneRepos does.
+func syncWorkRepos(repos []WorkRepo, auth string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	sem := make(chan struct{}, cloneConcurrency)
+	errs := make([]error, len(repos))
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		i, repo := i, repo
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			errs[i] = syncWorkRepo(repo, filepath.Join(home, repo.Dest), auth)
+		}()
+	}
+	wg.Wait()
+	var failed []string
+	for i, err := range errs {
+		if err != nil {
+			logger.Error("sync %s: %v", repos[i].URL, err)
+			failed = append(failed, repos[i].URL)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d repositories failed to sync: %s",
+			len(failed), len(repos), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// syncWorkRepo clones one repository with the chosen auth, or
+// fast-forwards an existing clone; local changes are never touched.
+func syncWorkRepo(repo WorkRepo, dest, auth string) error {
+	if _, err := os.Stat(dest); err == nil {
+		return installer.Run(fmt.Sprintf("git -C %q pull --ff-only", dest))
+	}
+	url := repo.URL
+	branch := ""
+	if repo.Branch != "" {
+		branch = fmt.Sprintf(" -b %q", repo.Branch)
+	}
+	if auth == RepoAuthGh {
+		flags := ""
+		if branch != "" {
+			flags = " --" + branch
+		}
+		return installer.Run(fmt.Sprintf("gh repo clone %q %q%s", url, dest, flags))
+	}
+	if auth == RepoAuthSSH {
+		url = strings.Replace(url, "https://github.com/", "git@github.com:", 1)
+	}
+	return installer.Run(fmt.Sprintf("git clone%s %q %q", branch, url, dest))
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ishida722/setup/components"
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+)
+
+// runDotfiles manages the configuration repositories on their own:
+// `setup dotfiles status` shows per-entry drift, `setup dotfiles
+// sync` converges them without touching any other component.
+func runDotfiles(args []string) error {
+	op := "status"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		op = args[0]
+		args = args[1:]
+	}
+	fs := flag.NewFlagSet("dotfiles", flag.ExitOnError)
+	spec := fs.String("entries", "", `semicolon-separated "url dest [source]" entries overriding the defaults`)
+	fs.Parse(args)
+
+	entries := components.DefaultDotfiles
+	if *spec != "" {
+		parsed, err := components.ParseDotfiles(*spec)
+		if err != nil {
+			logger.Error("%v", err)
+			return err
+		}
+		entries = parsed
+	}
+
+	switch op {
+	case "status":
+		w := tabwriter.NewWriter(os.Stdout, 2, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "ENTRY\tSTATE")
+		for _, s := range components.DotfilesStatus(entries) {
+			fmt.Fprintf(w, "%s\t%s\n", s.Dest, s.State)
+		}
+		return w.Flush()
+	case "sync":
+		stop := trapSignals()
+		defer stop()
+		results, err := installer.RunAllResults([]installer.InstallCommand{components.Dotfiles(entries)})
+		printSummary(results)
+		return err
+	default:
+		err := fmt.Errorf("unknown dotfiles operation %q (want status or sync)", op)
+		logger.Error("%v", err)
+		return err
+	}
+}
@@ -0,0 +1,70 @@
+//go:build e2e
+
+// End-to-end tests provisioning fresh Ubuntu containers with the real
+// binary, catching regressions like renamed release tarballs or
+// NodeSource script changes. They need docker and network access:
+//
+//	go test -tags e2e -timeout 30m ./e2e
+package e2e
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+var images = []string{"ubuntu:22.04", "ubuntu:24.04"}
+
+// buildBinary compiles setup for linux/amd64 into dir.
+func buildBinary(t *testing.T, dir string) string {
+	t.Helper()
+	bin := filepath.Join(dir, "setup")
+	cmd := exec.Command("go", "build", "-o", bin, "..")
+	cmd.Env = append(cmd.Environ(), "CGO_ENABLED=0", "GOOS=linux", "GOARCH=amd64")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+	return bin
+}
+
+// runInContainer starts a throwaway container with the binary mounted
+// and executes script under bash.
+func runInContainer(t *testing.T, image, bin, script string) []byte {
+	t.Helper()
+	cmd := exec.Command("docker", "run", "--rm",
+		"-v", bin+":/usr/local/bin/setup:ro",
+		image, "bash", "-ec", script)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("container run on %s: %v\n%s", image, err, out)
+	}
+	return out
+}
+
+func TestFullProfileOnFreshUbuntu(t *testing.T) {
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("docker not available")
+	}
+	bin := buildBinary(t, t.TempDir())
+	for _, image := range images {
+		image := image
+		t.Run(image, func(t *testing.T) {
+			t.Parallel()
+			runInContainer(t, image, bin, `
+apt-get update
+apt-get install -y sudo curl ca-certificates unzip
+setup install -yes -quiet-output -home-skeleton
+# The catalog must leave working tools behind.
+command -v node && node --version
+command -v nvim && nvim --version
+command -v fish && fish -c exit
+command -v lazygit && lazygit --version
+command -v gh && gh --version
+command -v yazi
+test -d ~/src && test -d ~/.local/bin
+# Status must agree the machine converged.
+setup status -home-skeleton
+`)
+		})
+	}
+}
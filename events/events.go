@@ -0,0 +1,45 @@
+// Package events emits machine-readable lifecycle events (one JSON
+// object per line) so CI wrappers and UIs can track a run in real time
+// without parsing the human log.
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Event is one NDJSON line. Fields beyond Event and Time are optional
+// and depend on the event type.
+type Event struct {
+	Time       time.Time `json:"time"`
+	Event      string    `json:"event"`
+	Component  string    `json:"component,omitempty"`
+	Version    string    `json:"version,omitempty"`
+	DurationMS int64     `json:"duration_ms,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Emitter serializes events to a writer. Safe for concurrent use.
+type Emitter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewEmitter writes NDJSON events to w.
+func NewEmitter(w io.Writer) *Emitter {
+	return &Emitter{enc: json.NewEncoder(w)}
+}
+
+// Emit writes one event, stamping the current time. Encoding errors
+// are ignored: events must never fail a run.
+func (e *Emitter) Emit(ev Event) {
+	if e == nil {
+		return
+	}
+	ev.Time = time.Now()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_ = e.enc.Encode(ev)
+}
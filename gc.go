@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ishida722/setup/components"
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+	"github.com/ishida722/setup/state"
+)
+
+// cacheMaxAge is how long unused download-cache entries are kept.
+const cacheMaxAge = 30 * 24 * time.Hour
+
+// gcItem is one leftover setup created in a past run but no longer
+// uses.
+type gcItem struct {
+	Path   string
+	Reason string
+	Size   int64
+}
+
+// runGC finds artifacts past versions of setup created that nothing
+// references anymore — superseded /opt/nvim-* and /opt/yazi-* trees,
+// downloaded installers in /tmp, stale config backups, old cache
+// entries — and offers to remove them.
+func runGC(args []string) error {
+	fs := flag.NewFlagSet("gc", flag.ExitOnError)
+	yes := fs.Bool("yes", false, "remove without prompting")
+	fs.Parse(args)
+
+	items := gcCandidates()
+	if len(items) == 0 {
+		logger.Success("nothing to clean up")
+		return nil
+	}
+	var total int64
+	for _, item := range items {
+		fmt.Printf("%-50s %8s  %s\n", item.Path, humanSize(item.Size), item.Reason)
+		total += item.Size
+	}
+	fmt.Printf("\n%d items, %s total\n", len(items), humanSize(total))
+
+	if !*yes {
+		if !logger.IsTerminal(os.Stdin) {
+			logger.Info("re-run with -yes to remove")
+			return nil
+		}
+		fmt.Printf("Remove? [y/N] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			logger.Info("nothing removed")
+			return nil
+		}
+	}
+	for _, item := range items {
+		if err := removePath(item.Path); err != nil {
+			logger.Error("remove %s: %v", item.Path, err)
+			continue
+		}
+		logger.Success("removed %s", item.Path)
+	}
+	return nil
+}
+
+// gcCandidates collects everything safe to delete. Each entry has a
+// reason, so the user can see why it is considered garbage.
+func gcCandidates() []gcItem {
+	var items []gcItem
+	items = append(items, supersededOptTrees("nvim")...)
+	items = append(items, supersededOptTrees("yazi")...)
+	items = append(items, supersededOptTrees("terraform")...)
+	items = append(items, supersededOptTrees("tofu")...)
+
+	installers := []string{
+		"/tmp/nodesource_setup.sh",
+		"/tmp/yazi.zip",
+		"/tmp/lazygit.tar.gz",
+		"/tmp/lazygit",
+	}
+	if tarball := components.NeovimTarball(); tarball != "" {
+		installers = append(installers, tarball)
+	}
+	for _, path := range installers {
+		if info, err := os.Stat(path); err == nil {
+			items = append(items, gcItem{Path: path, Reason: "downloaded installer, no longer needed", Size: info.Size()})
+		}
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		for _, repo := range components.DefaultDotfiles {
+			for _, suffix := range []string{".bak", ".old", ".backup"} {
+				path := filepath.Join(home, repo.Dest+suffix)
+				if _, err := os.Stat(path); err == nil {
+					items = append(items, gcItem{Path: path, Reason: "stale config backup", Size: treeSize(path)})
+				}
+			}
+		}
+	}
+
+	if dir, err := state.CacheDir(); err == nil {
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			info, err := e.Info()
+			if err != nil || time.Since(info.ModTime()) < cacheMaxAge {
+				continue
+			}
+			items = append(items, gcItem{
+				Path:   filepath.Join(dir, e.Name()),
+				Reason: fmt.Sprintf("cache entry unused for %d days", int(time.Since(info.ModTime()).Hours()/24)),
+				Size:   info.Size(),
+			})
+		}
+	}
+	return items
+}
+
+// supersededOptTrees finds /opt/<tool>-* trees that the current
+// /usr/local/bin symlink does not point into — left behind when a
+// newer release was extracted next to them.
+func supersededOptTrees(tool string) []gcItem {
+	current, _ := os.Readlink("/usr/local/bin/" + tool)
+	matches, _ := filepath.Glob("/opt/" + tool + "-*")
+	var items []gcItem
+	for _, path := range matches {
+		if current != "" && strings.HasPrefix(current, path+"/") {
+			continue
+		}
+		items = append(items, gcItem{Path: path, Reason: "superseded " + tool + " release", Size: treeSize(path)})
+	}
+	return items
+}
+
+// removePath deletes a file or tree, falling back to sudo for
+// root-owned paths under /opt.
+func removePath(path string) error {
+	err := os.RemoveAll(path)
+	if err == nil {
+		return nil
+	}
+	if os.IsPermission(err) {
+		return installer.Run(fmt.Sprintf("sudo rm -rf %q", path))
+	}
+	return err
+}
+
+func treeSize(root string) int64 {
+	var total int64
+	filepath.Walk(root, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+func humanSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,10 @@
+module github.com/ishida722/setup
+
+go 1.21
+
+require (
+	go.starlark.net v0.0.0-20240123142251-f86470692795
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+	"github.com/ishida722/setup/state"
+)
+
+// runHistory lists recorded runs, or shows one run in detail with the
+// version changes it caused.
+func runHistory(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	show := fs.Int("run", 0, "show details of run #N from the list")
+	fs.Parse(args)
+
+	runs := state.LoadRuns()
+	if len(runs) == 0 {
+		logger.Info("no runs recorded yet")
+		return nil
+	}
+	if *show > 0 {
+		if *show > len(runs) {
+			logger.Error("history: run #%d does not exist (%d recorded)", *show, len(runs))
+			return fmt.Errorf("no run #%d", *show)
+		}
+		showRun(runs, *show-1)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 2, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tTIME\tOUTCOME\tINSTALLED\tFAILED\tPROFILE")
+	for i, r := range runs {
+		installed, failed := 0, 0
+		for _, s := range r.Steps {
+			switch s.Action {
+			case "installed":
+				installed++
+			case "failed":
+				failed++
+			}
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\n", i+1,
+			r.Time.Format("2006-01-02 15:04"), r.Outcome, installed, failed,
+			strings.Join(r.Args, " "))
+	}
+	return w.Flush()
+}
+
+// showRun prints one run's steps, marking components whose version
+// differs from the last earlier run that recorded one.
+func showRun(runs []state.Run, i int) {
+	r := runs[i]
+	fmt.Printf("run #%d  %s  %s\n", i+1, r.Time.Format("2006-01-02 15:04"), r.Outcome)
+	if len(r.Args) > 0 {
+		fmt.Printf("profile: %s\n", strings.Join(r.Args, " "))
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "\nCOMPONENT\tACTION\tVERSION\tCHANGED")
+	for _, s := range r.Steps {
+		changed := ""
+		if prev := previousVersion(runs[:i], s.Name); prev != "" && s.Version != "" && prev != s.Version {
+			changed = prev + " -> " + s.Version
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, s.Action, s.Version, changed)
+	}
+	w.Flush()
+}
+
+// previousVersion finds the component's version in the most recent run
+// before this one that recorded it.
+func previousVersion(earlier []state.Run, name string) string {
+	for i := len(earlier) - 1; i >= 0; i-- {
+		for _, s := range earlier[i].Steps {
+			if s.Name == name && s.Version != "" {
+				return s.Version
+			}
+		}
+	}
+	return ""
+}
+
+// recordRun appends this run to the history.
+func recordRun(args []string, results []installer.Result, err error) {
+	r := state.Run{Time: time.Now(), Args: args, Outcome: "ok"}
+	if err != nil {
+		r.Outcome = err.Error()
+	}
+	for _, res := range results {
+		r.Steps = append(r.Steps, state.RunStep{
+			Name:       res.Name,
+			Action:     res.Action,
+			Version:    res.Version,
+			DurationMS: res.Duration.Milliseconds(),
+		})
+	}
+	state.AppendRun(r)
+}
@@ -0,0 +1,77 @@
+package installer
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/ishida722/setup/logger"
+	"github.com/ishida722/setup/shellwords"
+)
+
+// aptState tracks whether apt metadata has been refreshed during this
+// run. Several components start with `sudo apt-get update`; on slow
+// mirrors each refresh costs minutes, so only the first one runs. A
+// component that modifies /etc/apt (new repository or keyring) marks
+// the metadata stale again.
+var aptState struct {
+	mu      sync.Mutex
+	updated bool
+}
+
+func resetAptState() {
+	aptState.mu.Lock()
+	aptState.updated = false
+	aptState.mu.Unlock()
+}
+
+// isAptUpdate reports whether the command is solely an apt metadata
+// refresh (allowing sudo and flags). Compound commands and scripts
+// that refresh internally are never matched.
+func isAptUpdate(command string) bool {
+	words, err := shellwords.Split(command)
+	if err != nil {
+		return false
+	}
+	if len(words) > 0 && words[0] == "sudo" {
+		words = words[1:]
+	}
+	if len(words) < 2 || (words[0] != "apt" && words[0] != "apt-get") {
+		return false
+	}
+	if words[1] != "update" {
+		return false
+	}
+	for _, w := range words[2:] {
+		if !strings.HasPrefix(w, "-") {
+			return false
+		}
+	}
+	return true
+}
+
+// skipAptUpdate reports whether the command is a redundant apt update.
+func skipAptUpdate(command string) bool {
+	if !isAptUpdate(command) {
+		return false
+	}
+	aptState.mu.Lock()
+	defer aptState.mu.Unlock()
+	if aptState.updated {
+		logger.Debug("apt metadata already refreshed this run, skipping: %s", command)
+		return true
+	}
+	return false
+}
+
+// noteAptCommand updates the freshness tracking after command ran
+// successfully.
+func noteAptCommand(command string) {
+	aptState.mu.Lock()
+	defer aptState.mu.Unlock()
+	switch {
+	case strings.Contains(command, "/etc/apt/") || strings.Contains(command, "add-apt-repository"):
+		aptState.updated = false
+	case isAptUpdate(command):
+		aptState.updated = true
+	}
+}
@@ -0,0 +1,37 @@
+package installer_test
+
+import (
+	"testing"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/installer/installertest"
+)
+
+func TestAptUpdateDeduplicatedWithinRun(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	fake := &installertest.FakeRunner{}
+	defer installertest.Install(fake)()
+
+	cmds := []installer.InstallCommand{
+		{Name: "a", InstallCommands: []string{"sudo apt-get update", "sudo apt-get install -y x"}},
+		{Name: "b", InstallCommands: []string{"sudo apt-get update", "sudo apt-get install -y y"}},
+		// Adding a repository makes the metadata stale again.
+		{Name: "c", InstallCommands: []string{
+			`echo deb | sudo tee /etc/apt/sources.list.d/c.list > /dev/null`,
+			"sudo apt-get update",
+			"sudo apt-get install -y z",
+		}},
+	}
+	if _, err := installer.RunAllResults(cmds); err != nil {
+		t.Fatalf("RunAllResults: %v", err)
+	}
+	updates := 0
+	for _, call := range fake.Calls {
+		if call == "sudo apt-get update" {
+			updates++
+		}
+	}
+	if updates != 2 {
+		t.Errorf("apt-get update ran %d times, want 2 (calls: %v)", updates, fake.Calls)
+	}
+}
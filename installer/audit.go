@@ -0,0 +1,58 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/ishida722/setup/logger"
+	"github.com/ishida722/setup/shellwords"
+)
+
+// AuditLog is the append-only file recording every privileged command
+// setup executes, for compliance trails on provisioned hosts. Set to
+// "" to disable.
+var AuditLog = "/var/log/setup/audit.log"
+
+var sudoRe = regexp.MustCompile(`(^|[|&;(\s])sudo\s`)
+
+// privileged reports whether the command runs with elevated rights:
+// either through sudo or because setup itself runs as root. Detection
+// tokenizes the command so a quoted "sudo" inside an argument does not
+// count; commands the tokenizer rejects fall back to the regexp.
+func privileged(command string) bool {
+	if os.Geteuid() == 0 {
+		return true
+	}
+	words, err := shellwords.Split(command)
+	if err != nil {
+		return sudoRe.MatchString(command)
+	}
+	for _, w := range words {
+		if w == "sudo" {
+			return true
+		}
+	}
+	return false
+}
+
+// audit appends one line (timestamp, exact argv, exit code) to the
+// audit log. Failures are reported at debug level only: an unwritable
+// audit log (e.g. unprivileged dry runs) must not break provisioning.
+func audit(argv []string, exitCode int) {
+	if AuditLog == "" {
+		return
+	}
+	if err := os.MkdirAll("/var/log/setup", 0o755); err != nil {
+		logger.Debug("audit log unavailable: %v", err)
+		return
+	}
+	f, err := os.OpenFile(AuditLog, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		logger.Debug("audit log unavailable: %v", err)
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "%s exit=%d argv=%q\n", time.Now().Format(time.RFC3339), exitCode, argv)
+}
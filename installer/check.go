@@ -0,0 +1,64 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Check is a native probe evaluated in-process, for the common cases
+// where spawning a shell per check dominates `setup status` over a
+// large catalog. Shell returns the equivalent command so script export
+// and remote execution still work.
+type Check interface {
+	OK() bool
+	Shell() string
+}
+
+// BinaryExists checks that a binary is on PATH.
+type BinaryExists string
+
+func (b BinaryExists) OK() bool {
+	_, err := exec.LookPath(string(b))
+	return err == nil
+}
+
+func (b BinaryExists) Shell() string {
+	return fmt.Sprintf("command -v %s", string(b))
+}
+
+// PathExists checks that a file or directory exists. A leading ~/ is
+// expanded to the user's home directory.
+type PathExists string
+
+func (p PathExists) OK() bool {
+	_, err := os.Stat(expandHome(string(p)))
+	return err == nil
+}
+
+func (p PathExists) Shell() string {
+	return fmt.Sprintf("test -e %q", string(p))
+}
+
+// GitRepoAt checks that the directory is a git clone. A leading ~/ is
+// expanded to the user's home directory.
+type GitRepoAt string
+
+func (g GitRepoAt) OK() bool {
+	fi, err := os.Stat(filepath.Join(expandHome(string(g)), ".git"))
+	return err == nil && fi.IsDir()
+}
+
+func (g GitRepoAt) Shell() string {
+	return fmt.Sprintf("test -d %q", filepath.Join(string(g), ".git"))
+}
+
+func expandHome(path string) string {
+	if len(path) >= 2 && path[:2] == "~/" {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
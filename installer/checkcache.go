@@ -0,0 +1,60 @@
+package installer
+
+import "sync"
+
+// checkCache remembers check command results within a run, keyed by
+// the command string, so repeated Installed calls (plan, status
+// reporting, version lookups) don't spawn the same probe process
+// twice. It is only active inside runPlan: outside a run nothing
+// bounds how long a result stays valid.
+var checkCache struct {
+	mu      sync.Mutex
+	enabled bool
+	results map[string]error
+}
+
+func enableCheckCache() {
+	checkCache.mu.Lock()
+	checkCache.enabled = true
+	checkCache.results = map[string]error{}
+	checkCache.mu.Unlock()
+}
+
+func disableCheckCache() {
+	checkCache.mu.Lock()
+	checkCache.enabled = false
+	checkCache.results = nil
+	checkCache.mu.Unlock()
+}
+
+// runCheck executes one check command through the cache.
+func runCheck(command string) error {
+	checkCache.mu.Lock()
+	if checkCache.enabled {
+		if err, ok := checkCache.results[command]; ok {
+			checkCache.mu.Unlock()
+			return err
+		}
+	}
+	checkCache.mu.Unlock()
+
+	err := Run(command)
+
+	checkCache.mu.Lock()
+	if checkCache.enabled {
+		checkCache.results[command] = err
+	}
+	checkCache.mu.Unlock()
+	return err
+}
+
+// invalidateChecks drops the cached results for the component's check
+// commands, called after its install step runs and may have changed
+// their outcome.
+func invalidateChecks(c InstallCommand) {
+	checkCache.mu.Lock()
+	for _, cmd := range c.CheckCommands {
+		delete(checkCache.results, cmd)
+	}
+	checkCache.mu.Unlock()
+}
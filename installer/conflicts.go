@@ -0,0 +1,41 @@
+package installer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CheckConflicts refuses a selection containing components that
+// declare each other incompatible, before anything is installed.
+// Declarations are one-directional but checked symmetrically, so one
+// side declaring the conflict is enough.
+func CheckConflicts(cmds []InstallCommand) error {
+	selected := map[string]bool{}
+	for _, c := range cmds {
+		selected[c.Name] = true
+	}
+	seen := map[string]bool{}
+	var pairs []string
+	for _, c := range cmds {
+		for _, other := range c.ConflictsWith {
+			if !selected[other] {
+				continue
+			}
+			a, b := c.Name, other
+			if a > b {
+				a, b = b, a
+			}
+			key := a + " / " + b
+			if !seen[key] {
+				seen[key] = true
+				pairs = append(pairs, key)
+			}
+		}
+	}
+	if len(pairs) == 0 {
+		return nil
+	}
+	sort.Strings(pairs)
+	return fmt.Errorf("conflicting components selected: %s", strings.Join(pairs, ", "))
+}
@@ -0,0 +1,26 @@
+package installer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckConflicts(t *testing.T) {
+	docker := InstallCommand{Name: "Docker", ConflictsWith: []string{"Podman"}}
+	podman := InstallCommand{Name: "Podman"}
+
+	if err := CheckConflicts([]InstallCommand{docker}); err != nil {
+		t.Fatalf("undisputed selection refused: %v", err)
+	}
+	err := CheckConflicts([]InstallCommand{docker, podman})
+	if err == nil {
+		t.Fatal("conflicting selection accepted")
+	}
+	if !strings.Contains(err.Error(), "Docker / Podman") {
+		t.Fatalf("error does not name the pair: %v", err)
+	}
+	// One-directional declaration, reversed order: still caught once.
+	if err := CheckConflicts([]InstallCommand{podman, docker}); err == nil {
+		t.Fatal("reverse order not caught")
+	}
+}
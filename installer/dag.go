@@ -0,0 +1,258 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ishida722/setup/events"
+	"github.com/ishida722/setup/logger"
+	"github.com/ishida722/setup/state"
+)
+
+// aptMu serializes apt-using components during parallel runs: dpkg
+// allows a single frontend, so concurrent apt installs would just
+// fight over its lock.
+var aptMu sync.Mutex
+
+// usesApt detects components that will talk to apt/dpkg. Detection is
+// textual and conservative; a false positive only costs parallelism.
+func usesApt(c InstallCommand) bool {
+	for _, cmd := range c.InstallCommands {
+		if strings.Contains(cmd, "apt-get") || strings.Contains(cmd, "apt ") || strings.Contains(cmd, "dpkg") {
+			return true
+		}
+	}
+	// Verified scripts running with sudo are almost always apt
+	// repository bootstraps (NodeSource, cloud CLI vendors).
+	for _, v := range c.VerifiedScripts {
+		if v.Sudo {
+			return true
+		}
+	}
+	return false
+}
+
+// RunParallel installs cmds concurrently with at most concurrency
+// installs in flight, respecting DependsOn edges. Command output is
+// routed through per-component prefix writers so interleaved lines
+// stay attributable. Like RunAll, a failing component does not stop
+// independent ones, but everything depending on it is marked failed
+// without running; the first error is returned for the exit status.
+// A dependency cycle fails before anything executes.
+func RunParallel(cmds []InstallCommand, concurrency int) ([]Result, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	byName := map[string]int{}
+	for i, c := range cmds {
+		byName[c.Name] = i
+	}
+	// Dependencies outside the selection are ignored: a narrowed run
+	// (`setup install "Claude Code"`) trusts the user about what is
+	// already in place.
+	depsLeft := make([]int, len(cmds))
+	dependents := make([][]int, len(cmds))
+	for i, c := range cmds {
+		for _, d := range c.DependsOn {
+			j, ok := byName[d]
+			if !ok {
+				logger.Debug("%s: dependency %q not in this run, assuming present", c.Name, d)
+				continue
+			}
+			depsLeft[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+	if err := checkCycles(cmds, depsLeft, dependents); err != nil {
+		return nil, err
+	}
+
+	enableCheckCache()
+	defer disableCheckCache()
+	resetAptState()
+	steps := PlanConcurrent(cmds)
+	prefetch := startPrefetch(steps)
+
+	n := len(cmds)
+	results := make([]Result, n)
+	ready := make(chan int, n)
+	var mu sync.Mutex
+	processed := 0
+	failed := make([]bool, n)
+	var firstErr error
+
+	// finish records one outcome and releases or fails its
+	// dependents; called with mu held.
+	var finish func(i int, r Result)
+	finish = func(i int, r Result) {
+		results[i] = r
+		if r.Action == "failed" {
+			failed[i] = true
+			if firstErr == nil {
+				firstErr = r.Err
+			}
+		}
+		processed++
+		for _, j := range dependents[i] {
+			depsLeft[j]--
+			if depsLeft[j] > 0 {
+				continue
+			}
+			if dep := failedDependency(cmds[j], failed, byName); dep != "" {
+				c := cmds[j]
+				err := fmt.Errorf("dependency %s failed", dep)
+				logger.Error("Skipping %s: %v", c.Name, err)
+				Events.Emit(events.Event{Event: "component_failed", Component: c.Name, Error: err.Error()})
+				finish(j, Result{Name: c.Name, Action: "failed", Err: err})
+				continue
+			}
+			ready <- j
+		}
+		if processed == n {
+			close(ready)
+		}
+	}
+
+	mu.Lock()
+	if n == 0 {
+		close(ready)
+	}
+	for i := range cmds {
+		if depsLeft[i] == 0 {
+			ready <- i
+		}
+	}
+	mu.Unlock()
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range ready {
+				r := runParallelStep(steps[i], i, prefetch)
+				mu.Lock()
+				finish(i, r)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !DryRun {
+		history := state.LoadDurations()
+		for _, r := range results {
+			if r.Action == "installed" {
+				history[r.Name] = r.Duration
+			}
+		}
+		state.SaveDurations(history)
+	}
+	return results, firstErr
+}
+
+// failedDependency returns the name of a failed dependency of c, or "".
+func failedDependency(c InstallCommand, failed []bool, byName map[string]int) string {
+	for _, d := range c.DependsOn {
+		if j, ok := byName[d]; ok && failed[j] {
+			return d
+		}
+	}
+	return ""
+}
+
+// checkCycles rejects dependency cycles with Kahn's algorithm over a
+// scratch copy of the edge counts, naming the components involved.
+func checkCycles(cmds []InstallCommand, depsLeft []int, dependents [][]int) error {
+	left := append([]int(nil), depsLeft...)
+	queue := []int{}
+	for i := range cmds {
+		if left[i] == 0 {
+			queue = append(queue, i)
+		}
+	}
+	seen := 0
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		seen++
+		for _, j := range dependents[i] {
+			if left[j]--; left[j] == 0 {
+				queue = append(queue, j)
+			}
+		}
+	}
+	if seen == len(cmds) {
+		return nil
+	}
+	var cycle []string
+	for i, c := range cmds {
+		if left[i] > 0 {
+			cycle = append(cycle, c.Name)
+		}
+	}
+	return fmt.Errorf("dependency cycle among: %s", strings.Join(cycle, ", "))
+}
+
+// runParallelStep executes one step the way runPlan does, minus the
+// sequential-only niceties (ETA, spinner) and with prefixed output
+// always on.
+func runParallelStep(step Step, index int, prefetch *prefetcher) Result {
+	c := step.Command
+	if step.Action == "skip" {
+		logger.Skip("%s is already installed, skipping", c.Name)
+		version := c.Version()
+		Events.Emit(events.Event{Event: "component_skipped", Component: c.Name, Version: version})
+		return Result{Name: c.Name, Action: "skipped", Version: version}
+	}
+	if Interrupted() {
+		return Result{Name: c.Name, Action: "failed", Err: ErrInterrupted}
+	}
+	logger.Info("Installing %s...", c.Name)
+	Events.Emit(events.Event{Event: "component_started", Component: c.Name})
+	start := time.Now()
+	pw := logger.NewPrefixWriter(Output, c.Name, index)
+	var outW, errW io.Writer = pw, pw
+	var versionOut *logger.BoundedBuffer
+	if c.VersionFromOutput != "" {
+		versionOut = logger.NewBoundedBuffer(outputCaptureLimit)
+		outW = io.MultiWriter(outW, versionOut)
+		errW = io.MultiWriter(errW, versionOut)
+	}
+	failTail := logger.NewBoundedBuffer(outputCaptureLimit)
+	outW = io.MultiWriter(outW, failTail)
+	errW = io.MultiWriter(errW, failTail)
+	prefetch.wait(c)
+	serialize := usesApt(c)
+	if serialize {
+		aptMu.Lock()
+	}
+	err := c.InstallTo(outW, errW)
+	if serialize {
+		aptMu.Unlock()
+	}
+	pw.Close()
+	invalidateChecks(c)
+	elapsed := time.Since(start).Round(100 * time.Millisecond)
+	if err != nil {
+		logger.Error("Failed to install %s: %v", c.Name, err)
+		Events.Emit(events.Event{Event: "component_failed", Component: c.Name, DurationMS: elapsed.Milliseconds(), Error: err.Error()})
+		return Result{Name: c.Name, Action: "failed", Duration: elapsed, Err: err, Output: failTail.String()}
+	}
+	logger.Default.With("duration", elapsed).Success("%s installed", c.Name)
+	version := ""
+	if versionOut != nil {
+		version = c.versionFromOutput(versionOut.String())
+	}
+	if version == "" {
+		version = c.Version()
+	}
+	Events.Emit(events.Event{Event: "component_success", Component: c.Name, Version: version, DurationMS: elapsed.Milliseconds()})
+	if !DryRun {
+		recordChecksums(c)
+	}
+	return Result{Name: c.Name, Action: "installed", Version: version, Duration: elapsed}
+}
@@ -0,0 +1,83 @@
+package installer
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRunParallelRespectsDependencies(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) func() error {
+		return func() error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+	cmds := []InstallCommand{
+		{Name: "node", InstallFunc: record("node")},
+		{Name: "claude", DependsOn: []string{"node"}, InstallFunc: record("claude")},
+		{Name: "lazygit", InstallFunc: record("lazygit")},
+	}
+	results, err := RunParallel(cmds, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("want 3 results, got %d", len(results))
+	}
+	var nodeAt, claudeAt int
+	for i, name := range order {
+		switch name {
+		case "node":
+			nodeAt = i
+		case "claude":
+			claudeAt = i
+		}
+	}
+	if claudeAt < nodeAt {
+		t.Fatalf("claude ran before its dependency: %v", order)
+	}
+}
+
+func TestRunParallelFailsDependentsOfFailure(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	ran := false
+	cmds := []InstallCommand{
+		{Name: "broken", InstallFunc: func() error { return errors.New("boom") }},
+		{Name: "dependent", DependsOn: []string{"broken"}, InstallFunc: func() error { ran = true; return nil }},
+		{Name: "independent", InstallFunc: func() error { return nil }},
+	}
+	results, err := RunParallel(cmds, 2)
+	if err == nil {
+		t.Fatal("expected the run to report failure")
+	}
+	if ran {
+		t.Fatal("dependent ran despite its dependency failing")
+	}
+	byName := map[string]Result{}
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+	if r := byName["dependent"]; r.Action != "failed" || !strings.Contains(r.Err.Error(), "dependency") {
+		t.Fatalf("dependent result: %+v", r)
+	}
+	if r := byName["independent"]; r.Action != "installed" {
+		t.Fatalf("independent result: %+v", r)
+	}
+}
+
+func TestRunParallelRejectsCycles(t *testing.T) {
+	cmds := []InstallCommand{
+		{Name: "a", DependsOn: []string{"b"}, InstallFunc: func() error { return nil }},
+		{Name: "b", DependsOn: []string{"a"}, InstallFunc: func() error { return nil }},
+	}
+	if _, err := RunParallel(cmds, 2); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("cycle not rejected: %v", err)
+	}
+}
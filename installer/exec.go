@@ -0,0 +1,126 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ishida722/setup/logger"
+)
+
+// Cmd is one shell command with execution options beyond the bare
+// string: a working directory, extra environment, a timeout, and an
+// opt-in sudo wrapper. Zero options behave exactly like RunTo, and
+// everything still flows through the shared runner pipeline — shell
+// execution, tracing, the audit log, and record/replay.
+type Cmd struct {
+	Command string
+	// Dir is the working directory; "" inherits the process's.
+	Dir string
+	// Env are KEY=value pairs added to the inherited environment.
+	Env []string
+	// Timeout kills the command's process group when exceeded; zero
+	// means no limit.
+	Timeout time.Duration
+	// Sudo wraps the command in `sudo -E bash -c`, keeping Env
+	// visible to the privileged child.
+	Sudo bool
+}
+
+// RunCmd executes the command with its options, inheriting stdio like
+// Run.
+func RunCmd(c Cmd) error {
+	return RunCmdTo(c, os.Stdout, os.Stderr)
+}
+
+// RunCmdTo executes the command with its options, output going to the
+// given writers.
+func RunCmdTo(c Cmd, stdout, stderr io.Writer) error {
+	logger.Debug("$ %s", c.flatten())
+	if r, ok := Runner.(cmdRunner); ok {
+		return r.RunCmd(c, stdout, stderr)
+	}
+	// Recording, replay, and test runners only understand command
+	// strings; they get the flattened form (minus the timeout, which
+	// a replay does not re-measure).
+	return Runner.Run(c.flatten(), stdout, stderr)
+}
+
+// cmdRunner is implemented by runners that execute structured
+// commands natively instead of via the flattened string.
+type cmdRunner interface {
+	RunCmd(c Cmd, stdout, stderr io.Writer) error
+}
+
+// flatten renders the options into an equivalent single shell line,
+// for runners (and humans reading logs) that only see strings.
+func (c Cmd) flatten() string {
+	command := c.Command
+	if c.Sudo {
+		command = fmt.Sprintf("sudo -E bash -c %q", command)
+	}
+	var parts []string
+	if c.Dir != "" {
+		parts = append(parts, fmt.Sprintf("cd %q", c.Dir))
+	}
+	for _, kv := range c.Env {
+		parts = append(parts, "export "+kv)
+	}
+	parts = append(parts, command)
+	return strings.Join(parts, " && ")
+}
+
+func (execRunner) RunCmd(c Cmd, stdout, stderr io.Writer) error {
+	ctx := context.Background()
+	cancel := func() {}
+	if c.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+	}
+	defer cancel()
+	var cmd *exec.Cmd
+	if c.Sudo {
+		cmd = exec.CommandContext(ctx, "sudo", "-E", "bash", "-c", c.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "bash", "-c", c.Command)
+	}
+	cmd.Dir = c.Dir
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if ownProcessGroup() {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		// Cancellation must reach the whole process group, or a shell
+		// pipeline survives its timeout.
+		cmd.Cancel = func() error {
+			return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		}
+	}
+	if Trace {
+		traceCommand(cmd)
+	}
+	err := cmd.Start()
+	if err == nil {
+		setCurrent(cmd)
+		err = cmd.Wait()
+		setCurrent(nil)
+	}
+	if c.Sudo || privileged(c.Command) {
+		exitCode := -1
+		if cmd.ProcessState != nil {
+			exitCode = cmd.ProcessState.ExitCode()
+		}
+		audit(cmd.Args, exitCode)
+	}
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s: %w", c.Timeout, err)
+	}
+	return err
+}
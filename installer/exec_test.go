@@ -0,0 +1,71 @@
+package installer_test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/installer/installertest"
+)
+
+func requireBash(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skipf("bash unavailable: %v", err)
+	}
+}
+
+func TestRunCmdToDirAndEnv(t *testing.T) {
+	requireBash(t)
+	dir := t.TempDir()
+	var out strings.Builder
+	err := installer.RunCmdTo(installer.Cmd{
+		Command: `echo "$PWD $GREETING"`,
+		Dir:     dir,
+		Env:     []string{"GREETING=hello"},
+	}, &out, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := strings.Fields(out.String())
+	if len(fields) != 2 || fields[0] != dir || fields[1] != "hello" {
+		t.Errorf("output = %q, want dir %q and greeting", out.String(), dir)
+	}
+}
+
+func TestRunCmdToTimeout(t *testing.T) {
+	requireBash(t)
+	var out strings.Builder
+	start := time.Now()
+	err := installer.RunCmdTo(installer.Cmd{
+		Command: "sleep 10",
+		Timeout: 100 * time.Millisecond,
+	}, &out, &out)
+	if err == nil || !strings.Contains(err.Error(), "timed out after") {
+		t.Fatalf("err = %v, want timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("command outlived its timeout: %s", elapsed)
+	}
+}
+
+func TestRunCmdFlattensForStringRunners(t *testing.T) {
+	fake := &installertest.FakeRunner{}
+	defer installertest.Install(fake)()
+	var out strings.Builder
+	err := installer.RunCmdTo(installer.Cmd{
+		Command: "make install",
+		Dir:     "/src/app",
+		Env:     []string{"CC=clang"},
+		Sudo:    true,
+	}, &out, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `cd "/src/app" && export CC=clang && sudo -E bash -c "make install"`
+	if len(fake.Calls) != 1 || fake.Calls[0] != want {
+		t.Errorf("flattened command = %q, want %q", fake.Calls, want)
+	}
+}
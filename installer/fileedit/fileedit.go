@@ -0,0 +1,147 @@
+// Package fileedit provides idempotent line- and block-level edits to
+// text files — the engine behind the managed rc-file blocks — so
+// custom InstallFuncs and scripts can modify configs the same way the
+// core modules do. Every edit reports whether it changed anything, and
+// Diff previews an edit without writing.
+package fileedit
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnsureLine appends line to the file unless an identical line already
+// exists; existing content is never reordered. The file is created if
+// missing. Returns whether the file changed.
+func EnsureLine(path, line string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	for _, existing := range strings.Split(string(data), "\n") {
+		if existing == line {
+			return false, nil
+		}
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+	data = append(data, line...)
+	data = append(data, '\n')
+	return true, os.WriteFile(path, data, 0o644)
+}
+
+// blockMarkers renders the comment markers delimiting a managed block.
+func blockMarkers(marker string) (begin, end string) {
+	return "# BEGIN " + marker, "# END " + marker
+}
+
+// EnsureBlock replaces the managed block named by marker (appending it
+// if absent), leaving everything outside the markers untouched.
+// Returns whether the file changed.
+func EnsureBlock(path, marker, content string) (bool, error) {
+	old, err := readFileOrEmpty(path)
+	if err != nil {
+		return false, err
+	}
+	updated := withBlock(old, marker, content)
+	if updated == old {
+		return false, nil
+	}
+	return true, os.WriteFile(path, []byte(updated), 0o644)
+}
+
+// RemoveBlock deletes the managed block named by marker, if present.
+// Returns whether the file changed.
+func RemoveBlock(path, marker string) (bool, error) {
+	old, err := readFileOrEmpty(path)
+	if err != nil {
+		return false, err
+	}
+	updated := withoutBlock(old, marker)
+	if updated == old {
+		return false, nil
+	}
+	return true, os.WriteFile(path, []byte(updated), 0o644)
+}
+
+// Diff previews what EnsureBlock would change as a unified-style diff,
+// without touching the file. Empty means the file is already in the
+// desired state.
+func Diff(path, marker, content string) (string, error) {
+	old, err := readFileOrEmpty(path)
+	if err != nil {
+		return "", err
+	}
+	return diffText(path, old, withBlock(old, marker, content)), nil
+}
+
+// withBlock returns the text with the managed block set to content.
+func withBlock(text, marker, content string) string {
+	begin, end := blockMarkers(marker)
+	out := withoutBlock(text, marker)
+	out = strings.TrimRight(out, "\n")
+	if out != "" {
+		out += "\n"
+	}
+	if !strings.HasSuffix(content, "\n") && content != "" {
+		content += "\n"
+	}
+	return out + begin + "\n" + content + end + "\n"
+}
+
+// withoutBlock returns the text with the managed block removed.
+func withoutBlock(text, marker string) string {
+	begin, end := blockMarkers(marker)
+	var kept []string
+	inBlock := false
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case strings.TrimSpace(line) == begin:
+			inBlock = true
+		case strings.TrimSpace(line) == end:
+			inBlock = false
+		case !inBlock:
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+func readFileOrEmpty(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// diffText renders a minimal unified-style diff: the common head and
+// tail are elided and the differing middle shown as -/+ lines, which
+// is enough for an operator to approve an rc-file edit.
+func diffText(path, old, new string) string {
+	if old == new {
+		return ""
+	}
+	oldLines := strings.Split(old, "\n")
+	newLines := strings.Split(new, "\n")
+	head := 0
+	for head < len(oldLines) && head < len(newLines) && oldLines[head] == newLines[head] {
+		head++
+	}
+	tail := 0
+	for tail < len(oldLines)-head && tail < len(newLines)-head &&
+		oldLines[len(oldLines)-1-tail] == newLines[len(newLines)-1-tail] {
+		tail++
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", path, path)
+	for _, line := range oldLines[head : len(oldLines)-tail] {
+		fmt.Fprintf(&b, "-%s\n", line)
+	}
+	for _, line := range newLines[head : len(newLines)-tail] {
+		fmt.Fprintf(&b, "+%s\n", line)
+	}
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package fileedit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnsureLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rc")
+	changed, err := EnsureLine(path, "export PATH=$PATH:~/bin")
+	if err != nil || !changed {
+		t.Fatalf("first EnsureLine: changed=%v err=%v", changed, err)
+	}
+	changed, err = EnsureLine(path, "export PATH=$PATH:~/bin")
+	if err != nil || changed {
+		t.Fatalf("second EnsureLine not idempotent: changed=%v err=%v", changed, err)
+	}
+}
+
+func TestEnsureAndRemoveBlock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rc")
+	if err := os.WriteFile(path, []byte("user content\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := EnsureBlock(path, "test", "first\n"); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := EnsureBlock(path, "test", "first\n"); err != nil || changed {
+		t.Fatalf("unchanged block rewritten: changed=%v err=%v", changed, err)
+	}
+	if _, err := EnsureBlock(path, "test", "second\n"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "first") || !strings.Contains(string(data), "second") {
+		t.Fatalf("block not replaced:\n%s", data)
+	}
+	if changed, err := RemoveBlock(path, "test"); err != nil || !changed {
+		t.Fatalf("RemoveBlock: changed=%v err=%v", changed, err)
+	}
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "BEGIN") || !strings.Contains(string(data), "user content") {
+		t.Fatalf("removal wrong:\n%s", data)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "app.conf")
+	vars := map[string]string{"Port": "8080"}
+	changed, err := Template(path, "port = {{.Port}}\n", vars)
+	if err != nil || !changed {
+		t.Fatalf("first Template: changed=%v err=%v", changed, err)
+	}
+	if changed, err := Template(path, "port = {{.Port}}\n", vars); err != nil || changed {
+		t.Fatalf("unchanged render rewritten: changed=%v err=%v", changed, err)
+	}
+	if _, err := Template(path, "port = {{.Port}}\n", map[string]string{"Port": "9090"}); err != nil {
+		t.Fatal(err)
+	}
+	backup, err := os.ReadFile(path + ".bak")
+	if err != nil || string(backup) != "port = 8080\n" {
+		t.Fatalf("backup wrong: %q err=%v", backup, err)
+	}
+	diff, err := TemplateDiff(path, "port = {{.Port}}\n", vars)
+	if err != nil || !strings.Contains(diff, "+port = 8080") {
+		t.Fatalf("TemplateDiff: %q err=%v", diff, err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "port = 9090\n" {
+		t.Fatalf("TemplateDiff wrote to the file: %q", data)
+	}
+}
+
+func TestDiffPreviewsWithoutWriting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rc")
+	if err := os.WriteFile(path, []byte("keep\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	diff, err := Diff(path, "test", "added\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "+added") || strings.Contains(diff, "-keep") {
+		t.Fatalf("unexpected diff:\n%s", diff)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "keep\n" {
+		t.Fatalf("Diff wrote to the file: %q", data)
+	}
+}
@@ -0,0 +1,71 @@
+package fileedit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/ishida722/setup/state"
+)
+
+// Template renders the text/template src with vars into dest, writing
+// only when the rendered content differs from what is on disk. The
+// previous version is kept next to it as dest.bak, and the rendered
+// hash is recorded as managed state so later runs can tell local edits
+// from setup's own writes. Returns whether the file changed.
+func Template(dest, src string, vars map[string]string) (bool, error) {
+	rendered, err := renderTemplate(dest, src, vars)
+	if err != nil {
+		return false, err
+	}
+	old, err := os.ReadFile(dest)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if exists && string(old) == rendered {
+		return false, nil
+	}
+	if exists {
+		if err := os.WriteFile(dest+".bak", old, 0o644); err != nil {
+			return false, fmt.Errorf("back up %s: %w", dest, err)
+		}
+	}
+	if err := os.WriteFile(dest, []byte(rendered), 0o644); err != nil {
+		return false, err
+	}
+	sum := sha256.Sum256([]byte(rendered))
+	state.RecordManagedFile(dest, hex.EncodeToString(sum[:]))
+	return true, nil
+}
+
+// TemplateDiff previews the change Template would make as a unified
+// diff, without writing anything; empty means the destination is
+// already up to date. This is what plan/dry-run modes show.
+func TemplateDiff(dest, src string, vars map[string]string) (string, error) {
+	rendered, err := renderTemplate(dest, src, vars)
+	if err != nil {
+		return "", err
+	}
+	old, err := readFileOrEmpty(dest)
+	if err != nil {
+		return "", err
+	}
+	return diffText(dest, old, rendered), nil
+}
+
+// renderTemplate executes the template against the vars map.
+func renderTemplate(dest, src string, vars map[string]string) (string, error) {
+	tmpl, err := template.New(dest).Parse(src)
+	if err != nil {
+		return "", fmt.Errorf("template %s: %w", dest, err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, vars); err != nil {
+		return "", fmt.Errorf("template %s: %w", dest, err)
+	}
+	return out.String(), nil
+}
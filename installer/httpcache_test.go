@@ -0,0 +1,89 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ishida722/setup/state"
+)
+
+func cachePaths(t *testing.T, url string) (body, meta, partial string) {
+	t.Helper()
+	dir, err := state.CacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(url))
+	body = filepath.Join(dir, hex.EncodeToString(sum[:16]))
+	return body, body + ".meta", body + ".partial"
+}
+
+func TestCachedGetRevalidates(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("artifact-content"))
+	}))
+	defer srv.Close()
+
+	for i := 0; i < 2; i++ {
+		path, err := cachedGet(srv.URL)
+		if err != nil {
+			t.Fatalf("cachedGet #%d: %v", i+1, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || string(data) != "artifact-content" {
+			t.Fatalf("cached body #%d = %q, %v", i+1, data, err)
+		}
+	}
+	if hits != 2 {
+		t.Errorf("server saw %d requests, want 2 (one full, one conditional)", hits)
+	}
+}
+
+func TestCachedGetResumesPartialDownload(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	const full = "0123456789abcdef"
+	var gotRange string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRange = r.Header.Get("Range")
+		if gotRange == "bytes=8-" && r.Header.Get("If-Range") == `"v1"` {
+			w.Header().Set("Content-Range", "bytes 8-15/16")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte(full[8:]))
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(full))
+	}))
+	defer srv.Close()
+
+	// Simulate an interrupted earlier attempt: half the body plus the
+	// validators it had received.
+	_, meta, partial := cachePaths(t, srv.URL)
+	os.WriteFile(partial, []byte(full[:8]), 0o644)
+	os.WriteFile(meta, []byte(`{"url":"`+srv.URL+`","etag":"\"v1\""}`), 0o644)
+
+	path, err := cachedGet(srv.URL)
+	if err != nil {
+		t.Fatalf("cachedGet: %v", err)
+	}
+	if gotRange != "bytes=8-" {
+		t.Errorf("Range header = %q, want bytes=8-", gotRange)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != full {
+		t.Fatalf("resumed body = %q, %v; want %q", data, err, full)
+	}
+}
@@ -0,0 +1,545 @@
+// Package installer defines the InstallCommand model described in
+// go-setup-spec.md and the logic that executes it with the same
+// idempotency and error tolerance as the Ansible playbook.
+package installer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ishida722/setup/events"
+	"github.com/ishida722/setup/logger"
+	"github.com/ishida722/setup/state"
+)
+
+// InstallCommand describes one component of the setup.
+type InstallCommand struct {
+	// Name identifies the component in log output.
+	Name string
+	// CheckCommands detect an existing installation. If every command
+	// exits successfully the component is considered installed and the
+	// install step is skipped. An empty list means the install commands
+	// are always run and must be idempotent themselves.
+	CheckCommands []string
+	// Checks are native probes (BinaryExists, PathExists, GitRepoAt)
+	// evaluated in-process, avoiding a shell spawn per check. They
+	// combine with CheckCommands: all must pass for the component to
+	// count as installed.
+	Checks []Check
+	// Resources declare pieces of machine state this component owns
+	// (see Resource). Out-of-sync resources are converged before the
+	// install commands run, and status reports their drift
+	// individually.
+	Resources []Resource
+	// InstallCommands are run in order to install the component.
+	InstallCommands []string
+	// VerifiedScripts are remote scripts (see VerifiedScript) run
+	// before InstallCommands, replacing curl-pipe-bash with download,
+	// verification, and a diff against the last-seen version.
+	VerifiedScripts []VerifiedScript
+	// Scripts are embedded shell scripts (see Script) run after
+	// InstallCommands, for steps too long to read as inline strings.
+	Scripts []Script
+	// InstallFunc is custom installation logic for components that
+	// cannot be expressed as shell commands. It runs after
+	// InstallCommands.
+	InstallFunc func() error
+	// VersionCommand, when set, is run to report the installed
+	// version in the run summary (first output line is used).
+	VersionCommand string
+	// VersionFromOutput is a regexp matched against the install
+	// output; its first capture group becomes the version, skipping
+	// the extra VersionCommand process when the installer already
+	// printed it (e.g. apt's "Setting up" lines).
+	VersionFromOutput string
+	// LatestVersion, when set, reports the newest version available
+	// upstream (apt candidate, npm registry, GitHub release), so
+	// `setup outdated` can compare without installing anything.
+	LatestVersion func() (string, error)
+	// License is the component's license (SPDX identifier where one
+	// exists), for the licenses report and policy checks.
+	License string
+	// GitHubRepo is the "owner/name" of the release repository for
+	// components installed from GitHub releases; update uses it to
+	// show the release notes between the installed and latest
+	// versions before upgrading.
+	GitHubRepo string
+	// ConflictsWith names components this one cannot coexist with
+	// (e.g. Docker vs Podman's docker shim); a selection containing
+	// both is refused before anything runs.
+	ConflictsWith []string
+	// DependsOn names components that must finish before this one
+	// starts when the run is parallel (e.g. Claude Code needs
+	// Node.js). The sequential runner ignores it: catalog order
+	// already satisfies every dependency.
+	DependsOn []string
+	// Domains lists the hosts this component downloads from, so the
+	// pre-flight checks can verify reachability before the run starts.
+	Domains []string
+	// Downloads are artifacts fetched concurrently at the start of the
+	// run; install commands find them at Dest, with their own download
+	// as fallback when the prefetch failed.
+	Downloads []Download
+	// Binaries lists files installed outside any package manager
+	// (GitHub-release binaries under /opt or /usr/local/bin); their
+	// checksums are recorded after install and verified by status.
+	Binaries []string
+	// PostCheckCommands verify the component actually works after
+	// installation (e.g. `nvim --headless +q`, `node -e ""`), so
+	// "installed but broken" surfaces immediately instead of at
+	// first use. All must succeed or the step counts as failed.
+	PostCheckCommands []string
+}
+
+// Result records what happened to one component during a run, for the
+// summary table and report outputs.
+type Result struct {
+	Name     string
+	Action   string // "installed", "skipped", or "failed"
+	Version  string
+	Duration time.Duration
+	Err      error
+	// Output holds a bounded head-and-tail capture of the step's
+	// combined stdout/stderr when it failed, so the failure can be
+	// diagnosed from a report after the terminal scrollback is gone.
+	Output string
+}
+
+// Version runs VersionCommand and returns its first output line.
+func (c InstallCommand) Version() string {
+	if c.VersionCommand == "" {
+		return ""
+	}
+	var buf strings.Builder
+	if RunTo(c.VersionCommand, &buf, io.Discard) != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(buf.String()), "\n")
+	return line
+}
+
+// versionFromOutput extracts the version from captured install output
+// using the VersionFromOutput regexp.
+func (c InstallCommand) versionFromOutput(out string) string {
+	re, err := regexp.Compile(c.VersionFromOutput)
+	if err != nil {
+		logger.Debug("%s: invalid VersionFromOutput %q: %v", c.Name, c.VersionFromOutput, err)
+		return ""
+	}
+	m := re.FindStringSubmatch(out)
+	if len(m) > 1 {
+		return m[1]
+	}
+	if len(m) == 1 {
+		return m[0]
+	}
+	return ""
+}
+
+// HasChecks reports whether the component can detect an existing
+// installation at all.
+func (c InstallCommand) HasChecks() bool {
+	return len(c.CheckCommands) > 0 || len(c.Checks) > 0 || len(c.Resources) > 0
+}
+
+// Installed reports whether every check succeeds.
+func (c InstallCommand) Installed() bool {
+	if !c.HasChecks() {
+		return false
+	}
+	for _, chk := range c.Checks {
+		if !chk.OK() {
+			return false
+		}
+	}
+	for _, r := range c.Resources {
+		if !r.InSync() {
+			return false
+		}
+	}
+	for _, cmd := range c.CheckCommands {
+		if runCheck(cmd) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Install runs the install commands and InstallFunc in order, stopping
+// at the first failure.
+func (c InstallCommand) Install() error {
+	return c.InstallTo(Output, ErrOutput)
+}
+
+// DryRun, when set, makes install steps print what they would execute
+// instead of running anything, so a plan can be reviewed on a machine
+// that must not be touched yet. Checks still run — they are read-only
+// and decide which steps appear at all.
+var DryRun bool
+
+// dryRunPreview prints the work InstallTo would perform.
+func (c InstallCommand) dryRunPreview(w io.Writer) {
+	for _, r := range c.Resources {
+		if !r.InSync() {
+			fmt.Fprintf(w, "would converge %s: %q -> %q\n", r.ID(), r.Current(), r.Desired())
+		}
+	}
+	for _, v := range c.VerifiedScripts {
+		fmt.Fprintf(w, "would run verified script %s\n", v.URL)
+	}
+	for _, cmd := range c.InstallCommands {
+		fmt.Fprintf(w, "would run: %s\n", cmd)
+	}
+	for _, s := range c.Scripts {
+		fmt.Fprintf(w, "would run script %s\n", s.Name)
+	}
+	if c.InstallFunc != nil {
+		fmt.Fprintf(w, "would run custom install logic\n")
+	}
+	for _, cmd := range c.PostCheckCommands {
+		fmt.Fprintf(w, "would verify: %s\n", cmd)
+	}
+}
+
+// InstallTo is Install with the command output routed to the given
+// writers, e.g. a per-component PrefixWriter during parallel runs.
+func (c InstallCommand) InstallTo(stdout, stderr io.Writer) error {
+	if DryRun {
+		c.dryRunPreview(stdout)
+		return nil
+	}
+	if err := c.convergeResources(); err != nil {
+		return err
+	}
+	for _, v := range c.VerifiedScripts {
+		if err := v.run(stdout, stderr); err != nil {
+			return err
+		}
+	}
+	for _, cmd := range c.InstallCommands {
+		if skipAptUpdate(cmd) {
+			continue
+		}
+		if err := RunTo(cmd, stdout, stderr); err != nil {
+			return err
+		}
+		noteAptCommand(cmd)
+	}
+	for _, s := range c.Scripts {
+		if err := s.run(stdout, stderr); err != nil {
+			return err
+		}
+	}
+	if c.InstallFunc != nil {
+		if err := c.InstallFunc(); err != nil {
+			return err
+		}
+	}
+	return c.PostCheck(stdout, stderr)
+}
+
+// PostCheck runs the post-install verification commands.
+func (c InstallCommand) PostCheck(stdout, stderr io.Writer) error {
+	for _, cmd := range c.PostCheckCommands {
+		if err := RunTo(cmd, stdout, stderr); err != nil {
+			return fmt.Errorf("post-install check %q failed: %w", cmd, err)
+		}
+	}
+	return nil
+}
+
+// Events, when set, receives a lifecycle event for every component
+// (started / skipped / success / failed).
+var Events *events.Emitter
+
+// PrefixOutput routes each component's command output through a
+// colored per-component PrefixWriter, keeping interleaved output
+// attributable when steps run concurrently.
+var PrefixOutput bool
+
+// QuietOutput captures command output instead of streaming it, so a
+// run shows only the step start/finish lines. When a step fails its
+// captured output is dumped, keeping failures debuggable.
+var QuietOutput bool
+
+// outputCaptureLimit bounds captured step output (head and tail each):
+// a verbose installer must not grow memory with its output.
+const outputCaptureLimit = 64 << 10
+
+// Step is one entry of an execution plan: a component and what the
+// runner will do with it.
+type Step struct {
+	Command InstallCommand
+	// Action is "install" or "skip".
+	Action string
+}
+
+// Plan returns the exact sequence RunAll will execute for cmds, in
+// order: components whose checks already pass become "skip" steps, the
+// rest "install". The runner executes the plan as returned, so the
+// same component list always produces the same sequence and tests can
+// assert on the plan instead of on timing-dependent output.
+func Plan(cmds []InstallCommand) []Step {
+	steps := make([]Step, 0, len(cmds))
+	for _, c := range cmds {
+		action := "install"
+		if c.Installed() {
+			action = "skip"
+		}
+		steps = append(steps, Step{Command: c, Action: action})
+	}
+	return steps
+}
+
+// planConcurrency bounds parallel check evaluation in PlanConcurrent.
+const planConcurrency = 4
+
+// PlanConcurrent is Plan with the per-component checks evaluated in
+// parallel; the step order is unchanged. Checks are read-only, so over
+// a large selection `setup plan` finishes in the time of the slowest
+// check instead of the sum.
+func PlanConcurrent(cmds []InstallCommand) []Step {
+	steps := make([]Step, len(cmds))
+	sem := make(chan struct{}, planConcurrency)
+	var wg sync.WaitGroup
+	for i, c := range cmds {
+		i, c := i, c
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			action := "install"
+			if c.Installed() {
+				action = "skip"
+			}
+			steps[i] = Step{Command: c, Action: action}
+		}()
+	}
+	wg.Wait()
+	return steps
+}
+
+// RunSteps executes a previously computed plan as-is, without
+// re-evaluating checks, so `setup apply` runs exactly what `setup
+// plan` recorded.
+func RunSteps(steps []Step) ([]Result, error) {
+	enableCheckCache()
+	defer disableCheckCache()
+	resetAptState()
+	return runPlan(steps)
+}
+
+// RunAll installs every command in order. A failing component is
+// reported but does not stop the rest of the setup; the first error is
+// returned so the caller can set the exit status.
+func RunAll(cmds []InstallCommand) error {
+	_, err := RunAllResults(cmds)
+	return err
+}
+
+// RunAllResults is RunAll returning one Result per component, so
+// callers can render summaries and reports.
+func RunAllResults(cmds []InstallCommand) ([]Result, error) {
+	enableCheckCache()
+	defer disableCheckCache()
+	resetAptState()
+	return runPlan(Plan(cmds))
+}
+
+// runPlan executes a plan produced by Plan.
+func runPlan(steps []Step) ([]Result, error) {
+	var firstErr error
+	results := make([]Result, 0, len(steps))
+	history := state.LoadDurations()
+	prefetch := startPrefetch(steps)
+	for i, step := range steps {
+		if Interrupted() {
+			if firstErr == nil {
+				firstErr = ErrInterrupted
+			}
+			break
+		}
+		c := step.Command
+		if step.Action == "skip" {
+			logger.Skip("%s is already installed, skipping", c.Name)
+			version := c.Version()
+			Events.Emit(events.Event{Event: "component_skipped", Component: c.Name, Version: version})
+			results = append(results, Result{Name: c.Name, Action: "skipped", Version: version})
+			continue
+		}
+		progress := logger.Default.With("step", fmt.Sprintf("%d/%d", i+1, len(steps)))
+		if eta := estimateRemaining(steps[i:], history); eta > 0 {
+			progress = progress.With("eta", "~"+eta.String())
+		}
+		progress.Info("Installing %s...", c.Name)
+		Events.Emit(events.Event{Event: "component_started", Component: c.Name})
+		start := time.Now()
+		outW, errW := Output, ErrOutput
+		var captured *logger.BoundedBuffer
+		var closer io.Closer
+		switch {
+		case QuietOutput:
+			captured = logger.NewBoundedBuffer(outputCaptureLimit)
+			outW, errW = captured, captured
+		case PrefixOutput:
+			pw := logger.NewPrefixWriter(Output, c.Name, i)
+			closer = pw
+			outW, errW = pw, pw
+		}
+		var versionOut *logger.BoundedBuffer
+		if c.VersionFromOutput != "" {
+			versionOut = logger.NewBoundedBuffer(outputCaptureLimit)
+			outW = io.MultiWriter(outW, versionOut)
+			errW = io.MultiWriter(errW, versionOut)
+		}
+		failTail := captured
+		if failTail == nil {
+			failTail = logger.NewBoundedBuffer(outputCaptureLimit)
+			outW = io.MultiWriter(outW, failTail)
+			errW = io.MultiWriter(errW, failTail)
+		}
+		install := func() error {
+			if closer != nil {
+				defer closer.Close()
+			}
+			return c.InstallTo(outW, errW)
+		}
+		prefetch.wait(c)
+		spinner := logger.StartSpinner(os.Stderr, c.Name)
+		err := install()
+		spinner.Stop()
+		invalidateChecks(c)
+		elapsed := time.Since(start).Round(100 * time.Millisecond)
+		if err != nil {
+			logger.Error("Failed to install %s: %v", c.Name, err)
+			if captured != nil && captured.Len() > 0 {
+				fmt.Fprintf(ErrOutput, "--- output of %s ---\n%s--- end of output ---\n", c.Name, captured.String())
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			Events.Emit(events.Event{Event: "component_failed", Component: c.Name, DurationMS: elapsed.Milliseconds(), Error: err.Error()})
+			results = append(results, Result{Name: c.Name, Action: "failed", Duration: elapsed, Err: err, Output: failTail.String()})
+			continue
+		}
+		logger.Default.With("duration", elapsed).Success("%s installed", c.Name)
+		version := ""
+		if versionOut != nil {
+			version = c.versionFromOutput(versionOut.String())
+		}
+		if version == "" {
+			version = c.Version()
+		}
+		Events.Emit(events.Event{Event: "component_success", Component: c.Name, Version: version, DurationMS: elapsed.Milliseconds()})
+		results = append(results, Result{Name: c.Name, Action: "installed", Version: version, Duration: elapsed})
+		if !DryRun {
+			history[c.Name] = elapsed
+			recordChecksums(c)
+		}
+	}
+	if !DryRun {
+		state.SaveDurations(history)
+	}
+	return results, firstErr
+}
+
+// estimateRemaining sums the recorded durations of the pending install
+// steps. Components never installed before contribute nothing, so
+// early estimates are a lower bound that improves with every run.
+func estimateRemaining(pending []Step, history map[string]time.Duration) time.Duration {
+	var total time.Duration
+	for _, step := range pending {
+		if step.Action == "install" {
+			total += history[step.Command.Name]
+		}
+	}
+	return total.Round(time.Second)
+}
+
+// Run executes one shell command, inheriting stdio so interactive
+// prompts (e.g. sudo) work.
+func Run(command string) error {
+	return RunTo(command, os.Stdout, os.Stderr)
+}
+
+// Output and ErrOutput are where component command output goes by
+// default. main points them at a MultiWriter to also capture a
+// transcript for error reports.
+var (
+	Output    io.Writer = os.Stdout
+	ErrOutput io.Writer = os.Stderr
+)
+
+// Trace echoes every command exactly as executed (bash -x style, with
+// the working directory and any explicit environment) before running
+// it, regardless of verbosity.
+var Trace bool
+
+// CommandRunner executes one shell command. The default runs through
+// bash; tests substitute a fake via the installertest package.
+type CommandRunner interface {
+	Run(command string, stdout, stderr io.Writer) error
+}
+
+// Runner is the process-wide CommandRunner.
+var Runner CommandRunner = execRunner{}
+
+// execRunner is the real runner: bash -c with tracing and the
+// privileged-command audit log.
+type execRunner struct{}
+
+func (execRunner) Run(command string, stdout, stderr io.Writer) error {
+	cmd := exec.Command("bash", "-c", command)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if ownProcessGroup() {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	}
+	if Trace {
+		traceCommand(cmd)
+	}
+	err := cmd.Start()
+	if err == nil {
+		setCurrent(cmd)
+		err = cmd.Wait()
+		setCurrent(nil)
+	}
+	if privileged(command) {
+		exitCode := -1
+		if cmd.ProcessState != nil {
+			exitCode = cmd.ProcessState.ExitCode()
+		}
+		audit(cmd.Args, exitCode)
+	}
+	return err
+}
+
+// RunTo executes one shell command with its output going to the given
+// writers. Stdin stays attached to the terminal for sudo prompts.
+func RunTo(command string, stdout, stderr io.Writer) error {
+	logger.Debug("$ %s", command)
+	return Runner.Run(command, stdout, stderr)
+}
+
+// traceCommand prints the exact invocation, distinctly dimmed so it is
+// never confused with command output.
+func traceCommand(cmd *exec.Cmd) {
+	wd := cmd.Dir
+	if wd == "" {
+		wd, _ = os.Getwd()
+	}
+	env := ""
+	for _, e := range cmd.Env {
+		env += e + " "
+	}
+	fmt.Fprintf(os.Stderr, "\033[90m+ cwd=%s %s%s\033[0m\n", wd, env, strings.Join(cmd.Args, " "))
+}
@@ -0,0 +1,139 @@
+package installer_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/installer/installertest"
+)
+
+func TestInstallRunsCommandsInOrder(t *testing.T) {
+	fake := &installertest.FakeRunner{}
+	defer installertest.Install(fake)()
+
+	c := installer.InstallCommand{
+		Name:            "example",
+		InstallCommands: []string{"first", "second"},
+	}
+	if err := c.InstallTo(io.Discard, io.Discard); err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if len(fake.Calls) != 2 || fake.Calls[0] != "first" || fake.Calls[1] != "second" {
+		t.Errorf("calls = %v, want [first second]", fake.Calls)
+	}
+}
+
+func TestInstallStopsAtFirstFailure(t *testing.T) {
+	boom := errors.New("boom")
+	fake := &installertest.FakeRunner{Errors: map[string]error{"second": boom}}
+	defer installertest.Install(fake)()
+
+	c := installer.InstallCommand{
+		Name:            "example",
+		InstallCommands: []string{"first", "second", "third"},
+	}
+	if err := c.InstallTo(io.Discard, io.Discard); !errors.Is(err, boom) {
+		t.Fatalf("Install error = %v, want %v", err, boom)
+	}
+	if len(fake.Calls) != 2 {
+		t.Errorf("calls = %v, want to stop after the failure", fake.Calls)
+	}
+}
+
+func TestPlanIsDeterministic(t *testing.T) {
+	fake := &installertest.FakeRunner{Errors: map[string]error{"check-b": errors.New("missing")}}
+	defer installertest.Install(fake)()
+
+	cmds := []installer.InstallCommand{
+		{Name: "a", CheckCommands: []string{"check-a"}},
+		{Name: "b", CheckCommands: []string{"check-b"}},
+		{Name: "c"},
+	}
+	want := []string{"a:skip", "b:install", "c:install"}
+	for run := 0; run < 2; run++ {
+		plan := installer.Plan(cmds)
+		if len(plan) != len(want) {
+			t.Fatalf("plan has %d steps, want %d", len(plan), len(want))
+		}
+		for i, step := range plan {
+			if got := step.Command.Name + ":" + step.Action; got != want[i] {
+				t.Errorf("run %d step %d = %s, want %s", run, i, got, want[i])
+			}
+		}
+	}
+}
+
+func TestVersionExtractedFromInstallOutput(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	fake := &installertest.FakeRunner{
+		Outputs: map[string]string{"apt install x": "Setting up x (1.2.3-1ubuntu1) ...\n"},
+	}
+	defer installertest.Install(fake)()
+
+	cmds := []installer.InstallCommand{{
+		Name:              "x",
+		InstallCommands:   []string{"apt install x"},
+		VersionCommand:    "x --version",
+		VersionFromOutput: `Setting up x \(([^)]*)\)`,
+	}}
+	results, err := installer.RunAllResults(cmds)
+	if err != nil {
+		t.Fatalf("RunAllResults: %v", err)
+	}
+	if results[0].Version != "1.2.3-1ubuntu1" {
+		t.Errorf("version = %q, want 1.2.3-1ubuntu1", results[0].Version)
+	}
+	for _, call := range fake.Calls {
+		if call == "x --version" {
+			t.Error("VersionCommand spawned although the output had the version")
+		}
+	}
+}
+
+func TestChecksCachedWithinRun(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	probeErr := errors.New("not installed")
+	fake := &installertest.FakeRunner{Errors: map[string]error{"probe": probeErr}}
+	defer installertest.Install(fake)()
+
+	// Two components share a check command; within one run it must be
+	// probed only once.
+	cmds := []installer.InstallCommand{
+		{Name: "a", CheckCommands: []string{"probe"}, InstallCommands: []string{"install-a"}},
+		{Name: "b", CheckCommands: []string{"probe"}, InstallCommands: []string{"install-b"}},
+	}
+	if _, err := installer.RunAllResults(cmds); err != nil {
+		t.Fatalf("RunAllResults: %v", err)
+	}
+	probes := 0
+	for _, call := range fake.Calls {
+		if call == "probe" {
+			probes++
+		}
+	}
+	// Once during planning; the cache entry is invalidated after each
+	// install step, but nothing re-checks afterwards.
+	if probes != 1 {
+		t.Errorf("probe ran %d times, want 1 (calls: %v)", probes, fake.Calls)
+	}
+}
+
+func TestInstalledRequiresAllChecks(t *testing.T) {
+	fake := &installertest.FakeRunner{Errors: map[string]error{"check-b": errors.New("missing")}}
+	defer installertest.Install(fake)()
+
+	c := installer.InstallCommand{
+		Name:          "example",
+		CheckCommands: []string{"check-a", "check-b"},
+	}
+	if c.Installed() {
+		t.Error("Installed() = true with a failing check")
+	}
+
+	fake.Errors = nil
+	if !c.Installed() {
+		t.Error("Installed() = false with all checks passing")
+	}
+}
@@ -0,0 +1,55 @@
+// Package installertest provides a scriptable fake CommandRunner so
+// InstallCommands — including ones composed by downstream users — can
+// be unit-tested without executing real commands.
+package installertest
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// FakeRunner records every command it is asked to run and serves
+// canned outputs and injected failures instead of executing anything.
+type FakeRunner struct {
+	mu sync.Mutex
+	// Outputs maps a command string to the stdout it should produce.
+	Outputs map[string]string
+	// Errors maps a command string to the error it should fail with.
+	Errors map[string]error
+	// Strict makes any command missing from Outputs and Errors an
+	// error, for tests asserting the exact command set.
+	Strict bool
+	// Calls is every command run, in order.
+	Calls []string
+}
+
+// Run implements installer.CommandRunner.
+func (f *FakeRunner) Run(command string, stdout, stderr io.Writer) error {
+	f.mu.Lock()
+	f.Calls = append(f.Calls, command)
+	f.mu.Unlock()
+	if err, ok := f.Errors[command]; ok {
+		return err
+	}
+	out, ok := f.Outputs[command]
+	if !ok && f.Strict {
+		return fmt.Errorf("installertest: unexpected command %q", command)
+	}
+	if out != "" {
+		io.WriteString(stdout, out)
+	}
+	return nil
+}
+
+// Install swaps the fake in as the process-wide runner and returns a
+// restore function, for use as
+//
+//	defer installertest.Install(fake)()
+func Install(f *FakeRunner) (restore func()) {
+	prev := installer.Runner
+	installer.Runner = f
+	return func() { installer.Runner = prev }
+}
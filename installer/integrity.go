@@ -0,0 +1,68 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ishida722/setup/state"
+)
+
+// fileChecksum returns the SHA-256 of the file, resolving symlinks so
+// /usr/local/bin links into /opt hash the real binary.
+func fileChecksum(path string) (string, error) {
+	real, err := os.Readlink(path)
+	if err == nil {
+		path = real
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// recordChecksums stores the checksums of the component's binaries
+// after a successful install, for later integrity verification.
+func recordChecksums(c InstallCommand) {
+	if len(c.Binaries) == 0 {
+		return
+	}
+	sums := state.LoadChecksums()
+	for _, path := range c.Binaries {
+		if sum, err := fileChecksum(path); err == nil {
+			sums[path] = sum
+		}
+	}
+	state.SaveChecksums(sums)
+}
+
+// VerifyBinaries compares the component's binaries against the
+// checksums recorded at install time, flagging tampering or partial
+// extraction. Binaries never recorded are not reported.
+func (c InstallCommand) VerifyBinaries() []string {
+	var problems []string
+	sums := state.LoadChecksums()
+	for _, path := range c.Binaries {
+		want, ok := sums[path]
+		if !ok {
+			continue
+		}
+		got, err := fileChecksum(path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+		if got != want {
+			problems = append(problems, fmt.Sprintf("%s: checksum mismatch (recorded %.12s…, found %.12s…)", path, want, got))
+		}
+	}
+	return problems
+}
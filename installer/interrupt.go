@@ -0,0 +1,86 @@
+package installer
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"github.com/ishida722/setup/logger"
+)
+
+// ErrInterrupted is returned by RunAll when a signal stopped the run
+// between components.
+var ErrInterrupted = errors.New("run interrupted by signal")
+
+var interruptState struct {
+	mu          sync.Mutex
+	current     *exec.Cmd
+	interrupted bool
+	cleanups    []func()
+}
+
+// Interrupted reports whether Interrupt was called. The runner checks
+// it before every step so a run stops at the next component boundary.
+func Interrupted() bool {
+	interruptState.mu.Lock()
+	defer interruptState.mu.Unlock()
+	return interruptState.interrupted
+}
+
+// OnCleanup registers a function to run when the process is
+// interrupted, for components that must roll back half-finished work
+// (e.g. removing a partially written unit file). Cleanups run in
+// reverse registration order.
+func OnCleanup(fn func()) {
+	interruptState.mu.Lock()
+	defer interruptState.mu.Unlock()
+	interruptState.cleanups = append(interruptState.cleanups, fn)
+}
+
+// Interrupt stops the run: the currently executing command (and its
+// process group, when it has its own) receives SIGTERM, registered
+// cleanups run, and no further steps start. Safe to call from a signal
+// handler goroutine.
+func Interrupt() {
+	interruptState.mu.Lock()
+	if interruptState.interrupted {
+		interruptState.mu.Unlock()
+		return
+	}
+	interruptState.interrupted = true
+	cmd := interruptState.current
+	cleanups := interruptState.cleanups
+	interruptState.cleanups = nil
+	interruptState.mu.Unlock()
+
+	if cmd != nil && cmd.Process != nil {
+		pid := cmd.Process.Pid
+		if cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid {
+			pid = -pid // the whole group, so sudo/apt children die too
+		}
+		if err := syscall.Kill(pid, syscall.SIGTERM); err != nil && !errors.Is(err, syscall.ESRCH) {
+			logger.Debug("terminating current command: %v", err)
+		}
+	}
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
+
+// setCurrent tracks the command Interrupt should terminate.
+func setCurrent(cmd *exec.Cmd) {
+	interruptState.mu.Lock()
+	interruptState.current = cmd
+	interruptState.mu.Unlock()
+}
+
+// ownProcessGroup reports whether child commands should get their own
+// process group. Only done when stdin is not a terminal: there Ctrl-C
+// cannot reach children through the terminal, and SIGTERM to setup
+// alone would orphan running apt processes. Interactive runs keep the
+// shared group so sudo password prompts still work.
+func ownProcessGroup() bool {
+	return !logger.IsTerminal(os.Stdin)
+}
@@ -0,0 +1,103 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// manifestFile is the machine manifest schema: a declarative list of
+// components run through the same pipeline as the built-in catalog, so
+// per-machine tool sets (laptop, servers, WSL) live in version control
+// instead of requiring a recompile:
+//
+//	components:
+//	  - name: ripgrep
+//	    check_commands: ["command -v rg"]
+//	    install_commands: ["sudo apt-get install -y ripgrep"]
+//	  - name: dotfiles
+//	    config_repos:
+//	      - url: https://github.com/me/dotfiles
+//	        dest: ~/.config/dotfiles
+type manifestFile struct {
+	Components []manifestComponent `yaml:"components"`
+}
+
+// manifestComponent mirrors the InstallCommand fields expressible as
+// data; anything needing code stays in the Go catalog.
+type manifestComponent struct {
+	Name            string         `yaml:"name"`
+	CheckCommands   []string       `yaml:"check_commands"`
+	InstallCommands []string       `yaml:"install_commands"`
+	ConfigRepos     []manifestRepo `yaml:"config_repos"`
+	Domains         []string       `yaml:"domains"`
+	VersionCommand  string         `yaml:"version_command"`
+}
+
+// manifestRepo is one configuration repository to clone. Dest may
+// start with "~/" for the user's home directory.
+type manifestRepo struct {
+	URL  string `yaml:"url"`
+	Dest string `yaml:"dest"`
+}
+
+// LoadManifest reads and validates a YAML manifest, returning its
+// components ready for the install pipeline. Parsing is strict, so a
+// typoed key fails instead of being silently ignored.
+func LoadManifest(path string) ([]InstallCommand, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	var manifest manifestFile
+	if err := dec.Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	seen := map[string]bool{}
+	var cmds []InstallCommand
+	for i, mc := range manifest.Components {
+		if mc.Name == "" {
+			return nil, fmt.Errorf("%s: component #%d has no name", path, i+1)
+		}
+		if seen[mc.Name] {
+			return nil, fmt.Errorf("%s: duplicate component %q", path, mc.Name)
+		}
+		seen[mc.Name] = true
+		if len(mc.InstallCommands) == 0 && len(mc.ConfigRepos) == 0 {
+			return nil, fmt.Errorf("%s: %s: needs install_commands or config_repos", path, mc.Name)
+		}
+		cmd, err := mc.installCommand()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, mc.Name, err)
+		}
+		cmds = append(cmds, cmd)
+	}
+	return cmds, nil
+}
+
+// installCommand converts one manifest entry. Each config repo gets
+// its own presence check, so adding a repo to an entry re-runs it even
+// when everything else is already in place.
+func (mc manifestComponent) installCommand() (InstallCommand, error) {
+	cmd := InstallCommand{
+		Name:            mc.Name,
+		CheckCommands:   mc.CheckCommands,
+		InstallCommands: mc.InstallCommands,
+		Domains:         mc.Domains,
+		VersionCommand:  mc.VersionCommand,
+	}
+	for _, repo := range mc.ConfigRepos {
+		if repo.URL == "" || repo.Dest == "" {
+			return InstallCommand{}, fmt.Errorf("config repo needs url and dest")
+		}
+		dest := expandHome(repo.Dest)
+		cmd.CheckCommands = append(cmd.CheckCommands, fmt.Sprintf("test -d %q/.git", dest))
+		cmd.InstallCommands = append(cmd.InstallCommands,
+			fmt.Sprintf("test -d %q/.git || git clone %q %q", dest, repo.URL, dest))
+	}
+	return cmd, nil
+}
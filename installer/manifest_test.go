@@ -0,0 +1,60 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeManifest(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "setup.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadManifest(t *testing.T) {
+	path := writeManifest(t, `
+components:
+  - name: ripgrep
+    check_commands: ["command -v rg"]
+    install_commands: ["sudo apt-get install -y ripgrep"]
+  - name: dotfiles
+    config_repos:
+      - url: https://github.com/me/dotfiles
+        dest: ~/.config/dotfiles
+`)
+	cmds, err := LoadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cmds) != 2 || cmds[0].Name != "ripgrep" {
+		t.Fatalf("unexpected components: %+v", cmds)
+	}
+	home, _ := os.UserHomeDir()
+	dots := cmds[1]
+	if len(dots.CheckCommands) != 1 || !strings.Contains(dots.CheckCommands[0], home) {
+		t.Fatalf("~ not expanded in repo check: %v", dots.CheckCommands)
+	}
+	if len(dots.InstallCommands) != 1 || !strings.Contains(dots.InstallCommands[0], "git clone") {
+		t.Fatalf("repo clone missing: %v", dots.InstallCommands)
+	}
+}
+
+func TestLoadManifestRejectsBadInput(t *testing.T) {
+	cases := map[string]string{
+		"unknown key": "components:\n  - name: x\n    install_commands: [true]\n    intsall_commands: [true]\n",
+		"no name":     "components:\n  - install_commands: [true]\n",
+		"duplicate":   "components:\n  - name: x\n    install_commands: [true]\n  - name: x\n    install_commands: [true]\n",
+		"empty entry": "components:\n  - name: x\n",
+		"bare repo":   "components:\n  - name: x\n    config_repos:\n      - url: https://example.com/r\n",
+	}
+	for name, content := range cases {
+		if _, err := LoadManifest(writeManifest(t, content)); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package installer
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Platform identifies the host OS, distribution, and architecture, so
+// components can pick the right package backend and download the
+// release asset matching the machine.
+type Platform struct {
+	OS     string // runtime.GOOS
+	Distro string // /etc/os-release ID ("ubuntu", "fedora", "arch"); "macos" on darwin
+	Like   string // /etc/os-release ID_LIKE, for derivatives
+	Arch   string // runtime.GOARCH
+}
+
+var (
+	platformOnce sync.Once
+	platform     Platform
+)
+
+// DetectPlatform reports the host platform, detected once per process.
+func DetectPlatform() Platform {
+	platformOnce.Do(func() {
+		platform = Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+		if platform.OS == "darwin" {
+			platform.Distro = "macos"
+			return
+		}
+		if data, err := os.ReadFile("/etc/os-release"); err == nil {
+			platform.Distro, platform.Like = parseOSRelease(string(data))
+		}
+	})
+	return platform
+}
+
+// parseOSRelease extracts ID and ID_LIKE from os-release content.
+func parseOSRelease(data string) (id, like string) {
+	for _, line := range strings.Split(data, "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch key {
+		case "ID":
+			id = value
+		case "ID_LIKE":
+			like = value
+		}
+	}
+	return id, like
+}
+
+// PackageManager generates the shell commands of one system package
+// backend. The commands stay plain shell strings, so tracing, the
+// audit log, and record/replay apply to every backend unchanged.
+type PackageManager interface {
+	// Name is the backend's short name ("apt", "dnf", ...).
+	Name() string
+	// InstallCommand returns the command installing pkgs, idempotent
+	// where the backend allows it.
+	InstallCommand(pkgs ...string) string
+	// UpdateCommand refreshes the package index; "" when the backend
+	// needs no separate refresh.
+	UpdateCommand() string
+	// binary is the executable whose presence identifies the backend.
+	binary() string
+}
+
+type aptManager struct{}
+
+func (aptManager) Name() string          { return "apt" }
+func (aptManager) binary() string        { return "apt-get" }
+func (aptManager) UpdateCommand() string { return "sudo apt-get update" }
+func (aptManager) InstallCommand(pkgs ...string) string {
+	return "sudo apt-get install -y " + strings.Join(pkgs, " ")
+}
+
+type dnfManager struct{}
+
+func (dnfManager) Name() string          { return "dnf" }
+func (dnfManager) binary() string        { return "dnf" }
+func (dnfManager) UpdateCommand() string { return "" }
+func (dnfManager) InstallCommand(pkgs ...string) string {
+	return "sudo dnf install -y " + strings.Join(pkgs, " ")
+}
+
+type pacmanManager struct{}
+
+func (pacmanManager) Name() string          { return "pacman" }
+func (pacmanManager) binary() string        { return "pacman" }
+func (pacmanManager) UpdateCommand() string { return "sudo pacman -Sy" }
+func (pacmanManager) InstallCommand(pkgs ...string) string {
+	return "sudo pacman -S --noconfirm --needed " + strings.Join(pkgs, " ")
+}
+
+type brewManager struct{}
+
+func (brewManager) Name() string          { return "brew" }
+func (brewManager) binary() string        { return "brew" }
+func (brewManager) UpdateCommand() string { return "" }
+func (brewManager) InstallCommand(pkgs ...string) string {
+	return "brew install " + strings.Join(pkgs, " ")
+}
+
+// System returns the package manager for the detected platform: the
+// distribution's native one when recognized, otherwise whichever
+// backend's binary is on PATH. nil when none is found.
+func System() PackageManager {
+	p := DetectPlatform()
+	switch {
+	case p.Distro == "macos":
+		return brewManager{}
+	case p.Distro == "ubuntu" || p.Distro == "debian" || strings.Contains(p.Like, "debian") || strings.Contains(p.Like, "ubuntu"):
+		return aptManager{}
+	case p.Distro == "fedora" || strings.Contains(p.Like, "fedora") || strings.Contains(p.Like, "rhel"):
+		return dnfManager{}
+	case p.Distro == "arch" || strings.Contains(p.Like, "arch"):
+		return pacmanManager{}
+	}
+	for _, m := range []PackageManager{aptManager{}, dnfManager{}, pacmanManager{}, brewManager{}} {
+		if _, err := exec.LookPath(m.binary()); err == nil {
+			return m
+		}
+	}
+	return nil
+}
+
+// PkgInstall returns the native command installing pkgs, falling back
+// to apt when no backend is detected — the historical behavior, and
+// what every existing command string assumed.
+func PkgInstall(pkgs ...string) string {
+	if m := System(); m != nil {
+		return m.InstallCommand(pkgs...)
+	}
+	return aptManager{}.InstallCommand(pkgs...)
+}
+
+// PkgUpdate returns the native index refresh command, or "".
+func PkgUpdate() string {
+	if m := System(); m != nil {
+		return m.UpdateCommand()
+	}
+	return aptManager{}.UpdateCommand()
+}
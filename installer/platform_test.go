@@ -0,0 +1,36 @@
+package installer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseOSRelease(t *testing.T) {
+	id, like := parseOSRelease("NAME=\"Pop!_OS\"\nID=pop\nID_LIKE=\"ubuntu debian\"\n")
+	if id != "pop" || like != "ubuntu debian" {
+		t.Fatalf("parseOSRelease: id=%q like=%q", id, like)
+	}
+}
+
+func TestPackageManagerCommands(t *testing.T) {
+	cases := []struct {
+		m    PackageManager
+		want string
+	}{
+		{aptManager{}, "sudo apt-get install -y curl git"},
+		{dnfManager{}, "sudo dnf install -y curl git"},
+		{pacmanManager{}, "sudo pacman -S --noconfirm --needed curl git"},
+		{brewManager{}, "brew install curl git"},
+	}
+	for _, tc := range cases {
+		if got := tc.m.InstallCommand("curl", "git"); got != tc.want {
+			t.Errorf("%s: %q, want %q", tc.m.Name(), got, tc.want)
+		}
+	}
+}
+
+func TestPkgInstallAlwaysYieldsACommand(t *testing.T) {
+	if cmd := PkgInstall("git"); !strings.Contains(cmd, "git") {
+		t.Fatalf("PkgInstall: %q", cmd)
+	}
+}
@@ -0,0 +1,136 @@
+package installer
+
+import (
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/ishida722/setup/logger"
+)
+
+// Download is one artifact a component needs, fetched concurrently at
+// the start of the run so the network-bound downloads overlap with the
+// serialized apt steps.
+type Download struct {
+	URL  string
+	Dest string
+	// Mirrors are alternative URLs for the same artifact, tried when
+	// the primary fails, so a GitHub outage or a blocked domain falls
+	// back to an internal mirror instead of killing provisioning.
+	Mirrors []string
+}
+
+// prefetcher tracks the background downloads of one run. Every
+// download is best-effort: on failure the component's own curl
+// fallback runs in its install step and reports the real error.
+type prefetcher struct {
+	done map[string]chan struct{} // keyed by destination path
+}
+
+// prefetchConcurrency bounds parallel downloads so a slow link is not
+// saturated at the expense of the apt steps running alongside.
+const prefetchConcurrency = 3
+
+// startPrefetch begins downloading the artifacts of every component
+// the plan will install.
+func startPrefetch(steps []Step) *prefetcher {
+	p := &prefetcher{done: map[string]chan struct{}{}}
+	if DryRun {
+		return p
+	}
+	sem := make(chan struct{}, prefetchConcurrency)
+	for _, s := range steps {
+		if s.Action != "install" {
+			continue
+		}
+		for _, d := range s.Command.Downloads {
+			if _, ok := p.done[d.Dest]; ok {
+				continue
+			}
+			ch := make(chan struct{})
+			p.done[d.Dest] = ch
+			d := d
+			go func() {
+				defer close(ch)
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				if Interrupted() {
+					return
+				}
+				if err := fetch(d); err != nil {
+					logger.Debug("prefetch %s: %v", d.URL, err)
+				}
+			}()
+		}
+	}
+	return p
+}
+
+// wait blocks until the component's downloads have finished (in either
+// direction), so its install step sees a complete file or none at all.
+func (p *prefetcher) wait(c InstallCommand) {
+	for _, d := range c.Downloads {
+		if ch, ok := p.done[d.Dest]; ok {
+			<-ch
+		}
+	}
+}
+
+// fetch downloads one artifact through the HTTP cache and places it
+// at its destination, trying the primary URL and then each mirror. A
+// conditional hit costs one round trip and a local copy instead of
+// the full download.
+func fetch(d Download) error {
+	var lastErr error
+	for _, u := range orderByHealth(append([]string{d.URL}, d.Mirrors...)) {
+		cached, err := cachedGet(u)
+		if err != nil {
+			recordHostFailure(u)
+			logger.Debug("fetch %s: %v", u, err)
+			lastErr = err
+			continue
+		}
+		return copyFile(cached, d.Dest)
+	}
+	return lastErr
+}
+
+// hostFailures counts download failures per host within this run, so
+// mirror ordering can learn: once github.com has failed twice, later
+// artifacts try the mirror that has been working first instead of
+// waiting out the same timeout every time.
+var (
+	hostFailuresMu sync.Mutex
+	hostFailures   = map[string]int{}
+)
+
+func recordHostFailure(rawURL string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return
+	}
+	hostFailuresMu.Lock()
+	hostFailures[u.Host]++
+	hostFailuresMu.Unlock()
+}
+
+// orderByHealth sorts candidate URLs by their host's failure count
+// this run, preserving the declared order among equally healthy hosts
+// — so the primary still goes first until it actually misbehaves.
+func orderByHealth(urls []string) []string {
+	hostFailuresMu.Lock()
+	defer hostFailuresMu.Unlock()
+	sort.SliceStable(urls, func(i, j int) bool {
+		return failuresFor(urls[i]) < failuresFor(urls[j])
+	})
+	return urls
+}
+
+// failuresFor is called with hostFailuresMu held.
+func failuresFor(rawURL string) int {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0
+	}
+	return hostFailures[u.Host]
+}
@@ -0,0 +1,52 @@
+package installer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func resetHostFailures() {
+	hostFailuresMu.Lock()
+	hostFailures = map[string]int{}
+	hostFailuresMu.Unlock()
+}
+
+func TestFetchFallsBackToMirror(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	resetHostFailures()
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer primary.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("artifact-content"))
+	}))
+	defer mirror.Close()
+
+	dest := filepath.Join(t.TempDir(), "artifact")
+	d := Download{URL: primary.URL + "/a", Dest: dest, Mirrors: []string{mirror.URL + "/a"}}
+	if err := fetch(d); err != nil {
+		t.Fatalf("fetch with mirror: %v", err)
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil || string(data) != "artifact-content" {
+		t.Fatalf("destination = %q, %v", data, err)
+	}
+}
+
+func TestOrderByHealthPrefersWorkingHosts(t *testing.T) {
+	resetHostFailures()
+	urls := []string{"https://github.com/a", "https://mirror.example.com/a"}
+	got := orderByHealth(append([]string(nil), urls...))
+	if got[0] != urls[0] {
+		t.Fatalf("healthy hosts reordered: %v", got)
+	}
+	recordHostFailure("https://github.com/other")
+	got = orderByHealth(append([]string(nil), urls...))
+	if got[0] != urls[1] {
+		t.Fatalf("failed host not demoted: %v", got)
+	}
+}
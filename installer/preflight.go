@@ -0,0 +1,205 @@
+package installer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ishida722/setup/logger"
+)
+
+// minFreeBytes is the free space required in each install location. A
+// fresh run downloads a few hundred MB of tarballs and apt packages;
+// one GiB leaves headroom.
+const minFreeBytes = 1 << 30
+
+// Preflight checks the environment before a plan executes: supported
+// OS, disk space in /opt and $HOME, sudo credentials, the apt/dpkg
+// lock, a captive portal probe, and DNS plus HTTPS reachability of
+// every domain the selected components download from. All failures are
+// collected and returned together so one pass shows everything that
+// needs fixing.
+func Preflight(cmds []InstallCommand) []string {
+	var problems []string
+	if p := checkOS(); p != "" {
+		problems = append(problems, p)
+	}
+	home, _ := os.UserHomeDir()
+	for _, path := range []string{"/opt", home} {
+		if p := checkDiskSpace(path); p != "" {
+			problems = append(problems, p)
+		}
+	}
+	if p := checkSudo(); p != "" {
+		problems = append(problems, p)
+	}
+	if p := checkDpkgLock(); p != "" {
+		problems = append(problems, p)
+	}
+	if domains := planDomains(cmds); len(domains) > 0 {
+		if p := checkCaptivePortal(); p != "" {
+			problems = append(problems, p)
+		}
+		problems = append(problems, checkDomains(domains)...)
+	}
+	return problems
+}
+
+// planDomains collects the download domains of the given components,
+// deduplicated and sorted.
+func planDomains(cmds []InstallCommand) []string {
+	seen := map[string]bool{}
+	for _, c := range cmds {
+		for _, d := range c.Domains {
+			seen[d] = true
+		}
+	}
+	domains := make([]string, 0, len(seen))
+	for d := range seen {
+		domains = append(domains, d)
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// checkOS verifies a supported package manager exists for this
+// platform; the catalog leans on system packages throughout. Ubuntu
+// remains the primary target, but any platform with a known backend
+// (apt, dnf, pacman, brew) is allowed through.
+func checkOS() string {
+	if System() != nil {
+		return ""
+	}
+	p := DetectPlatform()
+	return fmt.Sprintf("unsupported platform %s/%s: none of apt, dnf, pacman, or brew found", p.OS, p.Distro)
+}
+
+// checkDiskSpace verifies the filesystem holding path has room for
+// downloads and extracted tools.
+func checkDiskSpace(path string) string {
+	if path == "" {
+		return ""
+	}
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(path, &fs); err != nil {
+		return ""
+	}
+	free := fs.Bavail * uint64(fs.Bsize)
+	if free < minFreeBytes {
+		return fmt.Sprintf("%s: only %d MB free, need %d MB", path, free>>20, minFreeBytes>>20)
+	}
+	return ""
+}
+
+// checkSudo verifies privileged commands will work. With a terminal
+// attached it validates interactively (caching the credentials for the
+// run); otherwise sudo must already work without a password.
+func checkSudo() string {
+	if os.Geteuid() == 0 {
+		return ""
+	}
+	if exec.Command("sudo", "-n", "true").Run() == nil {
+		return ""
+	}
+	if logger.IsTerminal(os.Stdin) {
+		cmd := exec.Command("sudo", "-v")
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if cmd.Run() == nil {
+			return ""
+		}
+	}
+	return "sudo credentials unavailable (run `sudo -v` first or configure NOPASSWD)"
+}
+
+// checkDpkgLock reports when another process holds the dpkg lock, so
+// the run fails up front instead of in the middle of the first apt
+// step. Being unable to test the lock (unprivileged) is not a failure.
+func checkDpkgLock() string {
+	f, err := os.OpenFile("/var/lib/dpkg/lock-frontend", os.O_RDWR, 0)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		return "apt/dpkg lock is held by another process (unattended-upgrades?)"
+	}
+	syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	return ""
+}
+
+// connectivityCheckURL is NetworkManager's probe endpoint: a plain
+// HTTP URL that answers 204 No Content on the open internet. A
+// captive portal intercepts it with a redirect to its login page. A
+// variable so tests can point it at a local server.
+var connectivityCheckURL = "http://connectivity-check.ubuntu.com"
+
+// checkCaptivePortal detects hotel/office Wi-Fi sign-in pages before
+// the run starts, so the user gets "open a browser and log in" instead
+// of cryptic curl and apt failures five minutes in. No response at all
+// is not a portal — the per-domain checks report plain outages.
+func checkCaptivePortal() string {
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(connectivityCheckURL)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNoContent {
+		return ""
+	}
+	if loc := resp.Header.Get("Location"); loc != "" {
+		return fmt.Sprintf("captive portal detected (login page at %s): sign in through a browser first", loc)
+	}
+	return fmt.Sprintf("captive portal suspected: connectivity probe got %s instead of 204 No Content", resp.Status)
+}
+
+// checkDomains verifies every download domain concurrently: first that
+// its name resolves (separating DNS problems from routing ones), then
+// that a real TLS handshake on port 443 succeeds — a portal or proxy
+// intercepting HTTPS shows up here as a certificate error.
+func checkDomains(domains []string) []string {
+	problems := make([]string, len(domains))
+	var wg sync.WaitGroup
+	for i, d := range domains {
+		i, d := i, d
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if _, err := net.DefaultResolver.LookupHost(ctx, d); err != nil {
+				problems[i] = fmt.Sprintf("%s: DNS resolution failed: %v", d, err)
+				return
+			}
+			conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 5 * time.Second}, "tcp", d+":443", nil)
+			if err != nil {
+				problems[i] = fmt.Sprintf("%s: HTTPS unreachable: %v", d, err)
+				return
+			}
+			conn.Close()
+		}()
+	}
+	wg.Wait()
+	out := problems[:0]
+	for _, p := range problems {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+package installer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckCaptivePortal(t *testing.T) {
+	cases := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string // substring of the problem, "" for no problem
+	}{
+		{
+			name: "open internet",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			want: "",
+		},
+		{
+			name: "portal redirect",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "https://portal.example.com/login", http.StatusFound)
+			},
+			want: "portal.example.com/login",
+		},
+		{
+			name: "intercepted response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("<html>welcome to the lobby</html>"))
+			},
+			want: "captive portal suspected",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(tc.handler)
+			defer srv.Close()
+			old := connectivityCheckURL
+			connectivityCheckURL = srv.URL
+			defer func() { connectivityCheckURL = old }()
+			got := checkCaptivePortal()
+			if tc.want == "" && got != "" {
+				t.Fatalf("unexpected problem: %q", got)
+			}
+			if tc.want != "" && !strings.Contains(got, tc.want) {
+				t.Fatalf("problem %q does not mention %q", got, tc.want)
+			}
+		})
+	}
+}
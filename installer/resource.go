@@ -0,0 +1,39 @@
+package installer
+
+// Resource is a declarative piece of machine state a component owns:
+// a package present at a version, a file with a given content hash,
+// the login shell. Where Check only answers installed-or-not, a
+// Resource also states what was desired and what is actually there,
+// so status can report drift per resource, and knows how to converge
+// the machine back to the desired state.
+//
+// Implementations live in the resources package; the interface is
+// defined here so InstallCommand can hold them without an import
+// cycle, mirroring Check.
+type Resource interface {
+	// ID names the resource in drift output, e.g. "login shell".
+	ID() string
+	// Desired describes the state the resource converges to.
+	Desired() string
+	// Current describes the state found on the machine.
+	Current() string
+	// InSync reports whether no convergence is needed.
+	InSync() bool
+	// Converge moves the machine to the desired state.
+	Converge() error
+}
+
+// convergeResources converges every out-of-sync resource, stopping at
+// the first failure. Resources already in the desired state are left
+// untouched, keeping convergence idempotent.
+func (c InstallCommand) convergeResources() error {
+	for _, r := range c.Resources {
+		if r.InSync() {
+			continue
+		}
+		if err := r.Converge(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Script is a shell script embedded in the binary (go:embed) and
+// executed as one install step — cleaner than a giant inline command
+// string for complex steps. The script is written to a temp file,
+// run through the usual runner with the given args and extra
+// environment, and removed afterwards.
+type Script struct {
+	// Name labels the script in logs and the temp file name,
+	// e.g. "install_lazygit.sh".
+	Name string
+	// Source is the script content, usually a go:embed variable.
+	Source []byte
+	// Args are passed as positional parameters.
+	Args []string
+	// Env are extra KEY=value pairs visible to the script.
+	Env []string
+}
+
+// run executes the script. Going through RunTo keeps tracing,
+// -record/-replay, and the audit log working for scripted steps.
+func (s Script) run(stdout, stderr io.Writer) error {
+	f, err := os.CreateTemp("", "setup-"+s.Name+"-*")
+	if err != nil {
+		return err
+	}
+	path := f.Name()
+	defer os.Remove(path)
+	if _, err := f.Write(s.Source); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	for _, kv := range s.Env {
+		key, value, _ := strings.Cut(kv, "=")
+		fmt.Fprintf(&b, "%s=%q ", key, value)
+	}
+	fmt.Fprintf(&b, "bash %q", path)
+	for _, arg := range s.Args {
+		fmt.Fprintf(&b, " %q", arg)
+	}
+	if err := RunTo(b.String(), stdout, stderr); err != nil {
+		return fmt.Errorf("script %s: %w", s.Name, err)
+	}
+	return nil
+}
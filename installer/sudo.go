@@ -0,0 +1,63 @@
+package installer
+
+import (
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/ishida722/setup/logger"
+)
+
+// sudoRefreshInterval is how often the cached sudo timestamp is
+// renewed. Well under the default timestamp_timeout of 15 minutes, so
+// the credentials never lapse mid-run.
+const sudoRefreshInterval = time.Minute
+
+// KeepSudoAlive validates sudo once and then refreshes the cached
+// timestamp in the background until the returned stop function is
+// called. Without it a long run can stall half-way on a password
+// prompt buried in apt output. Running as root, or when sudo cannot be
+// validated (preflight reports that separately), it does nothing.
+func KeepSudoAlive() (stop func()) {
+	if os.Geteuid() == 0 {
+		return func() {}
+	}
+	if validateSudo() != nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(sudoRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// -n: never prompt from the background; a failed
+				// refresh just means the next privileged command
+				// prompts as usual.
+				if err := exec.Command("sudo", "-n", "-v").Run(); err != nil {
+					logger.Debug("sudo refresh failed: %v", err)
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// validateSudo establishes the sudo timestamp, prompting on the
+// terminal when needed.
+func validateSudo() error {
+	if exec.Command("sudo", "-n", "true").Run() == nil {
+		return nil
+	}
+	if !logger.IsTerminal(os.Stdin) {
+		return exec.Command("sudo", "-n", "-v").Run()
+	}
+	cmd := exec.Command("sudo", "-v")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
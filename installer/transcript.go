@@ -0,0 +1,95 @@
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// transcriptEntry is one recorded command with its combined output and
+// result, stored as a JSON line.
+type transcriptEntry struct {
+	Command string `json:"command"`
+	Output  string `json:"output"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Record wraps the current Runner so every executed command and its
+// output is appended to a transcript file, which Replay can later
+// serve — the recording half of golden tests for whole profiles.
+func Record(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	Runner = &recordingRunner{inner: Runner, enc: json.NewEncoder(f)}
+	return nil
+}
+
+type recordingRunner struct {
+	mu    sync.Mutex
+	inner CommandRunner
+	enc   *json.Encoder
+}
+
+func (r *recordingRunner) Run(command string, stdout, stderr io.Writer) error {
+	var captured strings.Builder
+	err := r.inner.Run(command, io.MultiWriter(stdout, &captured), io.MultiWriter(stderr, &captured))
+	entry := transcriptEntry{Command: command, Output: captured.String()}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enc.Encode(entry)
+}
+
+// Replay replaces the Runner with one serving a recorded transcript:
+// commands must arrive in the recorded order and get the recorded
+// output and result without touching the network or sudo. A diverging
+// command fails loudly, which is exactly what a golden test wants.
+func Replay(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var entries []transcriptEntry
+	dec := json.NewDecoder(f)
+	for dec.More() {
+		var e transcriptEntry
+		if err := dec.Decode(&e); err != nil {
+			return fmt.Errorf("transcript %s: %w", path, err)
+		}
+		entries = append(entries, e)
+	}
+	Runner = &replayRunner{entries: entries}
+	return nil
+}
+
+type replayRunner struct {
+	mu      sync.Mutex
+	entries []transcriptEntry
+	next    int
+}
+
+func (r *replayRunner) Run(command string, stdout, stderr io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.next >= len(r.entries) {
+		return fmt.Errorf("replay: unexpected command %q after end of transcript", command)
+	}
+	entry := r.entries[r.next]
+	r.next++
+	if entry.Command != command {
+		return fmt.Errorf("replay: got command %q, transcript has %q", command, entry.Command)
+	}
+	io.WriteString(stdout, entry.Output)
+	if entry.Error != "" {
+		return fmt.Errorf("%s", entry.Error)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package installer_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/installer/installertest"
+)
+
+func TestRecordThenReplay(t *testing.T) {
+	transcript := filepath.Join(t.TempDir(), "run.transcript")
+	orig := installer.Runner
+	t.Cleanup(func() { installer.Runner = orig })
+
+	fake := &installertest.FakeRunner{Outputs: map[string]string{"greet": "hello\n"}}
+	restore := installertest.Install(fake)
+	if err := installer.Record(transcript); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	var out strings.Builder
+	if err := installer.RunTo("greet", &out, &out); err != nil {
+		t.Fatalf("recorded run: %v", err)
+	}
+	restore()
+
+	if err := installer.Replay(transcript); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	out.Reset()
+	if err := installer.RunTo("greet", &out, &out); err != nil {
+		t.Fatalf("replayed run: %v", err)
+	}
+	if out.String() != "hello\n" {
+		t.Errorf("replayed output = %q, want %q", out.String(), "hello\n")
+	}
+	if err := installer.RunTo("greet", &out, &out); err == nil {
+		t.Error("command beyond the transcript end should fail")
+	}
+}
+
+func TestReplayRejectsDivergingCommand(t *testing.T) {
+	transcript := filepath.Join(t.TempDir(), "run.transcript")
+	orig := installer.Runner
+	t.Cleanup(func() { installer.Runner = orig })
+	restore := installertest.Install(&installertest.FakeRunner{})
+	if err := installer.Record(transcript); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	var out strings.Builder
+	if err := installer.RunTo("expected", &out, &out); err != nil {
+		t.Fatalf("recorded run: %v", err)
+	}
+	restore()
+
+	if err := installer.Replay(transcript); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if err := installer.RunTo("different", &out, &out); err == nil {
+		t.Error("diverging command should fail the replay")
+	}
+}
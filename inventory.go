@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// InventoryGroup is one named group of remote hosts with the install
+// flags the group's machines share.
+type InventoryGroup struct {
+	Name  string
+	Hosts []string
+	Args  string
+}
+
+// ParseInventory reads an Ansible-style inventory: hosts listed under
+// [group] headers, with per-group install flags in an optional
+// [group:vars] section as `args = ...`. Blank lines and # comments are
+// ignored.
+//
+//	[web]
+//	deploy@web1.example.com
+//	deploy@web2.example.com
+//
+//	[web:vars]
+//	args = -ufw -mosh -node-exporter
+func ParseInventory(path string) (map[string]*InventoryGroup, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	groups := map[string]*InventoryGroup{}
+	group := func(name string) *InventoryGroup {
+		if g, ok := groups[name]; ok {
+			return g
+		}
+		g := &InventoryGroup{Name: name}
+		groups[name] = g
+		return g
+	}
+
+	section := ""
+	vars := false
+	scanner := bufio.NewScanner(f)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.Trim(line, "[]")
+			section, vars = strings.CutSuffix(section, ":vars")
+			continue
+		}
+		if section == "" {
+			return nil, fmt.Errorf("%s:%d: host %q outside any [group]", path, lineno, line)
+		}
+		if vars {
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("%s:%d: expected key = value in [%s:vars]", path, lineno, section)
+			}
+			key = strings.TrimSpace(key)
+			if key != "args" {
+				return nil, fmt.Errorf("%s:%d: unknown variable %q (only args is supported)", path, lineno, key)
+			}
+			group(section).Args = strings.TrimSpace(value)
+			continue
+		}
+		group(section).Hosts = append(group(section).Hosts, line)
+	}
+	return groups, scanner.Err()
+}
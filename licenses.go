@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+)
+
+// runLicenses lists the license of every selected component, for the
+// compliance report:
+//
+//	setup licenses
+func runLicenses(args []string) error {
+	cmds, err := selectComponents(flag.NewFlagSet("licenses", flag.ExitOnError), args)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "COMPONENT\tLICENSE\tINSTALLED")
+	for _, c := range cmds {
+		license := c.License
+		if license == "" {
+			license = "unknown"
+		}
+		installed := "-"
+		if c.HasChecks() {
+			if c.Installed() {
+				installed = "yes"
+			} else {
+				installed = "no"
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", c.Name, license, installed)
+	}
+	return w.Flush()
+}
+
+// checkLicensePolicy rejects components whose license the policy file
+// disallows. The file lists one disallowed license per line; blank
+// lines and #-comments are ignored. Components without recorded
+// licenses are flagged but not blocked — the personal config repos
+// have none.
+func checkLicensePolicy(path string, cmds []installer.InstallCommand) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	disallowed := map[string]bool{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		disallowed[strings.ToLower(line)] = true
+	}
+	var blocked []string
+	for _, c := range cmds {
+		if c.License == "" {
+			logger.Warn("license policy: %s has no recorded license", c.Name)
+			continue
+		}
+		if disallowed[strings.ToLower(c.License)] {
+			blocked = append(blocked, fmt.Sprintf("%s (%s)", c.Name, c.License))
+		}
+	}
+	if len(blocked) > 0 {
+		for _, b := range blocked {
+			logger.Error("license policy: %s", b)
+		}
+		return fmt.Errorf("%d components blocked by license policy", len(blocked))
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ishida722/setup/logger"
+	"github.com/ishida722/setup/state"
+)
+
+// runLockFile is the machine-wide lock serializing setup runs, so a
+// cron-driven update and a manual run do not fight over apt/dpkg and
+// the state files.
+const runLockFile = "/var/lock/setup.lock"
+
+// acquireRunLock takes the run lock (flock, non-blocking) and returns
+// a release function. When another invocation holds it, the error says
+// who has been holding it since when. Unprivileged runs that cannot
+// write /var/lock fall back to a lock in the user state directory.
+func acquireRunLock() (release func(), err error) {
+	path := runLockFile
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		dir, derr := state.Dir()
+		if derr != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, "setup.lock")
+		logger.Debug("cannot use %s (%v), locking %s instead", runLockFile, err, path)
+		if f, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644); err != nil {
+			return nil, err
+		}
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		holder, _ := os.ReadFile(path)
+		f.Close()
+		if info := strings.TrimSpace(string(holder)); info != "" {
+			return nil, fmt.Errorf("another setup run is in progress (%s)", info)
+		}
+		return nil, fmt.Errorf("another setup run is in progress (lock %s)", path)
+	}
+	// Record the holder for the error message of the losing side.
+	username := ""
+	if u, uerr := user.Current(); uerr == nil {
+		username = u.Username + " "
+	}
+	f.Truncate(0)
+	fmt.Fprintf(f, "%spid %d since %s", username, os.Getpid(), time.Now().Format(time.RFC3339))
+	f.Sync()
+	return func() {
+		f.Truncate(0)
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+)
+
+// BoundedBuffer is an io.Writer keeping the first and last `limit`
+// bytes of everything written, with the middle dropped. Verbose
+// installers (apt full-upgrade) can emit hundreds of megabytes;
+// capturing them for reports must not grow memory with the output.
+// The head usually holds the command banner and the tail the actual
+// error, which is what a report reader needs.
+type BoundedBuffer struct {
+	mu      sync.Mutex
+	limit   int
+	head    []byte
+	tail    []byte // ring buffer once head is full
+	tailPos int
+	wrapped bool
+	dropped int64
+}
+
+// NewBoundedBuffer returns a buffer retaining limit bytes of head and
+// limit bytes of tail.
+func NewBoundedBuffer(limit int) *BoundedBuffer {
+	return &BoundedBuffer{limit: limit}
+}
+
+func (b *BoundedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := len(p)
+	if room := b.limit - len(b.head); room > 0 {
+		take := room
+		if take > len(p) {
+			take = len(p)
+		}
+		b.head = append(b.head, p[:take]...)
+		p = p[take:]
+	}
+	if len(p) == 0 {
+		return n, nil
+	}
+	if b.tail == nil {
+		b.tail = make([]byte, b.limit)
+	}
+	for len(p) > 0 {
+		copied := copy(b.tail[b.tailPos:], p)
+		b.tailPos += copied
+		if b.tailPos == b.limit {
+			b.tailPos = 0
+			b.wrapped = true
+		}
+		p = p[copied:]
+		b.dropped += int64(copied)
+	}
+	return n, nil
+}
+
+// Len reports how much output would be rendered by Bytes.
+func (b *BoundedBuffer) Len() int {
+	return len(b.Bytes())
+}
+
+// Bytes renders the retained output, marking how much was dropped in
+// the middle.
+func (b *BoundedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var tail []byte
+	if b.wrapped {
+		tail = append(tail, b.tail[b.tailPos:]...)
+		tail = append(tail, b.tail[:b.tailPos]...)
+	} else {
+		tail = append(tail, b.tail[:b.tailPos]...)
+	}
+	omitted := b.dropped - int64(len(tail))
+	if omitted <= 0 {
+		return append(append([]byte{}, b.head...), tail...)
+	}
+	out := append([]byte{}, b.head...)
+	out = append(out, fmt.Sprintf("\n... [%d bytes omitted] ...\n", omitted)...)
+	return append(out, tail...)
+}
+
+func (b *BoundedBuffer) String() string {
+	return string(b.Bytes())
+}
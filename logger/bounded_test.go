@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestBoundedBufferBelowLimitIsVerbatim(t *testing.T) {
+	b := NewBoundedBuffer(16)
+	fmt.Fprint(b, "hello ")
+	fmt.Fprint(b, "world")
+	if got := b.String(); got != "hello world" {
+		t.Errorf("String() = %q, want %q", got, "hello world")
+	}
+}
+
+func TestBoundedBufferKeepsHeadAndTail(t *testing.T) {
+	b := NewBoundedBuffer(4)
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(b, "%d", i) // "0123456789"
+	}
+	got := b.String()
+	if !strings.HasPrefix(got, "0123") {
+		t.Errorf("head lost: %q", got)
+	}
+	if !strings.HasSuffix(got, "6789") {
+		t.Errorf("tail lost: %q", got)
+	}
+	if !strings.Contains(got, "[2 bytes omitted]") {
+		t.Errorf("omission marker missing or wrong: %q", got)
+	}
+}
+
+func TestBoundedBufferMemoryStaysFlat(t *testing.T) {
+	b := NewBoundedBuffer(1 << 10)
+	chunk := strings.Repeat("x", 4096)
+	for i := 0; i < 1024; i++ { // 4 MB through a 2 KB window
+		b.Write([]byte(chunk))
+	}
+	if got := len(b.Bytes()); got > 3<<10 {
+		t.Errorf("rendered %d bytes, want at most head+tail+marker", got)
+	}
+}
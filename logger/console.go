@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorBlue   = "\033[34m"
+	colorGray   = "\033[90m"
+)
+
+// ConsoleHandler renders records in the classic setup.sh style:
+// a colored [LEVEL] tag, the message, and any structured fields as a
+// dimmed key=value suffix. Errors go to a separate writer.
+type ConsoleHandler struct {
+	out, err io.Writer
+	level    slog.Leveler
+	attrs    []slog.Attr
+	mu       *sync.Mutex
+}
+
+// Verbosity is the minimum level console handlers emit; change it via
+// SetLevel (wired to the -v/-q flags).
+var Verbosity = func() *slog.LevelVar {
+	v := &slog.LevelVar{}
+	v.Set(slog.LevelInfo)
+	return v
+}()
+
+// SetLevel adjusts the console verbosity for the whole process.
+func SetLevel(level slog.Level) { Verbosity.Set(level) }
+
+// timestamps controls whether console lines start with a wall-clock
+// time; toggled by ShowTimestamps (wired to -timestamps).
+var timestamps bool
+
+// ShowTimestamps enables wall-clock timestamps on console output.
+func ShowTimestamps(on bool) { timestamps = on }
+
+// NewConsoleHandler builds the handler. A nil err sends errors to out.
+func NewConsoleHandler(out, err io.Writer) *ConsoleHandler {
+	if err == nil {
+		err = out
+	}
+	return &ConsoleHandler{out: out, err: err, level: Verbosity, mu: &sync.Mutex{}}
+}
+
+// Enabled implements slog.Handler.
+func (h *ConsoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle implements slog.Handler.
+func (h *ConsoleHandler) Handle(_ context.Context, rec slog.Record) error {
+	tag, color := levelTag(rec.Level)
+	var fields strings.Builder
+	elapsed := ""
+	field := func(a slog.Attr) {
+		// Per-step elapsed time reads better inline than as a field:
+		// [SUCCESS] (12.3s) Neovim installed
+		if a.Key == "duration" {
+			if d, ok := a.Value.Any().(time.Duration); ok {
+				elapsed = fmt.Sprintf("(%.1fs) ", d.Seconds())
+				return
+			}
+		}
+		fmt.Fprintf(&fields, " %s=%v", a.Key, a.Value)
+	}
+	for _, a := range h.attrs {
+		field(a)
+	}
+	rec.Attrs(func(a slog.Attr) bool {
+		field(a)
+		return true
+	})
+	suffix := ""
+	if fields.Len() > 0 {
+		suffix = theme.Fields + fields.String() + theme.reset(theme.Fields)
+	}
+	stamp := ""
+	if timestamps {
+		stamp = theme.Fields + rec.Time.Format("15:04:05") + theme.reset(theme.Fields) + " "
+	}
+
+	w := h.out
+	if rec.Level >= slog.LevelError {
+		w = h.err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := fmt.Fprintf(w, "%s%s[%s]%s %s%s%s\n", stamp, color, tag, theme.reset(color), elapsed, rec.Message, suffix)
+	return err
+}
+
+// WithAttrs implements slog.Handler.
+func (h *ConsoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &h2
+}
+
+// WithGroup implements slog.Handler; groups are flattened, which is
+// enough for console output.
+func (h *ConsoleHandler) WithGroup(string) slog.Handler { return h }
+
+func levelTag(level slog.Level) (string, string) {
+	switch {
+	case level >= slog.LevelError:
+		return "ERROR", theme.Error
+	case level >= slog.LevelWarn:
+		return "WARN", theme.Warn
+	case level >= LevelSuccess:
+		return "SUCCESS", theme.Success
+	case level >= LevelSkip:
+		return "SKIP", theme.Skip
+	case level >= slog.LevelInfo:
+		return "INFO", theme.Info
+	default:
+		return "DEBUG", theme.Debug
+	}
+}
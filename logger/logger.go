@@ -0,0 +1,105 @@
+// Package logger provides setup's log output on top of log/slog: a
+// colored INFO/SUCCESS/ERROR console handler (matching the original
+// setup.sh scheme) by default, with any structured slog handler (JSON,
+// files) pluggable behind the same API.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Custom levels between Info and Warn; slog has no native notion of a
+// completed or skipped step.
+const (
+	LevelSkip    = slog.Level(1)
+	LevelSuccess = slog.Level(2)
+)
+
+// Logger wraps an slog.Logger with setup's printf-style methods and
+// the Success level.
+type Logger struct {
+	sl *slog.Logger
+}
+
+// New returns a Logger emitting through the given slog handler.
+func New(h slog.Handler) *Logger {
+	return &Logger{sl: slog.New(h)}
+}
+
+// NewConsole returns a Logger with the colored human-readable handler.
+// A nil err sends errors to out as well.
+func NewConsole(out, err io.Writer) *Logger {
+	return New(NewConsoleHandler(out, err))
+}
+
+// NewJSON returns a Logger emitting structured JSON lines, for log
+// files and machine consumers.
+func NewJSON(w io.Writer) *Logger {
+	return New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.LevelKey {
+				if lv, ok := a.Value.Any().(slog.Level); ok && lv == LevelSuccess {
+					a.Value = slog.StringValue("SUCCESS")
+				}
+			}
+			return a
+		},
+	}))
+}
+
+// Default is the process-wide logger used by the package-level
+// functions. Replace it to redirect all setup output.
+var Default = NewConsole(os.Stdout, os.Stderr)
+
+// With returns a Logger carrying structured fields (e.g. component,
+// step) attached to every line.
+func (l *Logger) With(args ...any) *Logger {
+	return &Logger{sl: l.sl.With(args...)}
+}
+
+func (l *Logger) log(level slog.Level, format string, a ...any) {
+	l.sl.Log(context.Background(), level, fmt.Sprintf(format, a...))
+}
+
+// Debug logs details like raw command traces, hidden unless -v.
+func (l *Logger) Debug(format string, a ...any) { l.log(slog.LevelDebug, format, a...) }
+
+// Info logs a progress message.
+func (l *Logger) Info(format string, a ...any) { l.log(slog.LevelInfo, format, a...) }
+
+// Warn logs something surprising that does not fail the step, e.g. a
+// component skipped on this platform.
+func (l *Logger) Warn(format string, a ...any) { l.log(slog.LevelWarn, format, a...) }
+
+// Skip logs a step skipped because it is already done.
+func (l *Logger) Skip(format string, a ...any) { l.log(LevelSkip, format, a...) }
+
+// Success logs a completed step.
+func (l *Logger) Success(format string, a ...any) { l.log(LevelSuccess, format, a...) }
+
+// Error logs a failure. Setup continues after errors, so this does not
+// exit.
+func (l *Logger) Error(format string, a ...any) { l.log(slog.LevelError, format, a...) }
+
+// Debug logs a detail message via Default.
+func Debug(format string, a ...any) { Default.Debug(format, a...) }
+
+// Info logs a progress message via Default.
+func Info(format string, a ...any) { Default.Info(format, a...) }
+
+// Warn logs a warning via Default.
+func Warn(format string, a ...any) { Default.Warn(format, a...) }
+
+// Skip logs a skipped step via Default.
+func Skip(format string, a ...any) { Default.Skip(format, a...) }
+
+// Success logs a completed step via Default.
+func Success(format string, a ...any) { Default.Success(format, a...) }
+
+// Error logs a failure via Default.
+func Error(format string, a ...any) { Default.Error(format, a...) }
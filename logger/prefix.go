@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// prefixPalette colors component prefixes during parallel runs, in the
+// style of docker compose.
+var prefixPalette = []string{
+	"\033[36m", // cyan
+	"\033[35m", // magenta
+	"\033[33m", // yellow
+	"\033[32m", // green
+	"\033[34m", // blue
+	"\033[31m", // red
+}
+
+// PrefixWriter prefixes every line written through it with a colored
+// name, so interleaved output from concurrent steps stays
+// attributable. It is line-buffered; call Close to flush a trailing
+// partial line.
+type PrefixWriter struct {
+	w      io.Writer
+	prefix string
+	mu     sync.Mutex
+	buf    bytes.Buffer
+}
+
+// NewPrefixWriter returns a PrefixWriter writing to w. index selects
+// the prefix color from a fixed palette, so each component in a run
+// gets a stable color.
+func NewPrefixWriter(w io.Writer, name string, index int) *PrefixWriter {
+	color := prefixPalette[index%len(prefixPalette)]
+	return &PrefixWriter{w: w, prefix: color + name + colorReset + " | "}
+}
+
+// Write implements io.Writer.
+func (p *PrefixWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.buf.Write(b)
+	for {
+		line, err := p.buf.ReadString('\n')
+		if err != nil {
+			// Partial line; keep it buffered for the next write.
+			p.buf.WriteString(line)
+			break
+		}
+		if _, err := io.WriteString(p.w, p.prefix+line); err != nil {
+			return len(b), err
+		}
+	}
+	return len(b), nil
+}
+
+// Close flushes any trailing partial line.
+func (p *PrefixWriter) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.buf.Len() > 0 {
+		_, err := io.WriteString(p.w, p.prefix+p.buf.String()+"\n")
+		p.buf.Reset()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Spinner shows that a quiet step (git clone, tar extraction) is still
+// making progress, with elapsed time. A nil Spinner is inert, so call
+// sites need no TTY guards.
+type Spinner struct {
+	w    io.Writer
+	done chan struct{}
+}
+
+// StartSpinner animates `message (elapsed)` on w until Stop is called.
+// Returns nil (no-op) when w is not a terminal, e.g. in CI or when
+// output is piped.
+func StartSpinner(w io.Writer, message string) *Spinner {
+	if f, ok := w.(*os.File); !ok || !IsTerminal(f) {
+		return nil
+	}
+	s := &Spinner{w: w, done: make(chan struct{})}
+	go func() {
+		start := time.Now()
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			select {
+			case <-s.done:
+				fmt.Fprint(s.w, "\r\033[K")
+				s.done <- struct{}{}
+				return
+			case <-ticker.C:
+				frame := spinnerFrames[i%len(spinnerFrames)]
+				fmt.Fprintf(s.w, "\r\033[K%s %s %s(%ds)%s", frame, message,
+					colorGray, int(time.Since(start).Seconds()), colorReset)
+			}
+		}
+	}()
+	return s
+}
+
+// Stop clears the spinner line.
+func (s *Spinner) Stop() {
+	if s == nil {
+		return
+	}
+	s.done <- struct{}{}
+	<-s.done
+}
+
+// IsTerminal reports whether f is attached to a character device.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
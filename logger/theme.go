@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Theme holds the ANSI color per log tag. Values are full escape
+// sequences so themes can use bright/bold variants.
+type Theme struct {
+	Debug   string
+	Info    string
+	Skip    string
+	Success string
+	Warn    string
+	Error   string
+	Fields  string // dimmed key=value suffixes and timestamps
+}
+
+// themes are the built-in color themes. "light" uses high-contrast
+// colors readable on light terminal backgrounds, where the default
+// blue tends to vanish; "mono" disables colors entirely.
+var themes = map[string]Theme{
+	"default": {
+		Debug:   colorGray,
+		Info:    colorBlue,
+		Skip:    colorGray,
+		Success: colorGreen,
+		Warn:    colorYellow,
+		Error:   colorRed,
+		Fields:  colorGray,
+	},
+	"light": {
+		Debug:   "\033[90m",
+		Info:    "\033[1;34m",
+		Skip:    "\033[35m",
+		Success: "\033[1;32m",
+		Warn:    "\033[1;31m",
+		Error:   "\033[1;41;97m",
+		Fields:  "\033[90m",
+	},
+	"mono": {},
+}
+
+// theme is the active theme.
+var theme = themes["default"]
+
+// SetTheme activates a built-in theme by name.
+func SetTheme(name string) error {
+	t, ok := themes[name]
+	if !ok {
+		names := make([]string, 0, len(themes))
+		for n := range themes {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	theme = t
+	return nil
+}
+
+// SetThemeColors overrides individual tag colors of the active theme,
+// from "tag=color" pairs where color is an ANSI SGR parameter list
+// (e.g. "info=1;34", "error=91").
+func SetThemeColors(pairs []string) error {
+	for _, p := range pairs {
+		tag, value, ok := strings.Cut(p, "=")
+		if !ok {
+			return fmt.Errorf("invalid color override %q (want tag=colors)", p)
+		}
+		seq := "\033[" + value + "m"
+		switch strings.ToLower(tag) {
+		case "debug":
+			theme.Debug = seq
+		case "info":
+			theme.Info = seq
+		case "skip":
+			theme.Skip = seq
+		case "success":
+			theme.Success = seq
+		case "warn":
+			theme.Warn = seq
+		case "error":
+			theme.Error = seq
+		default:
+			return fmt.Errorf("unknown log tag %q", tag)
+		}
+	}
+	return nil
+}
+
+// reset returns the reset sequence, or "" for the mono theme so lines
+// stay free of escape codes.
+func (t Theme) reset(color string) string {
+	if color == "" {
+		return ""
+	}
+	return colorReset
+}
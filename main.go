@@ -0,0 +1,947 @@
+// Command setup provisions an Ubuntu development environment. It is
+// the Go implementation of setup.sh / playbook.yml (see
+// go-setup-spec.md).
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ishida722/setup/components"
+	"github.com/ishida722/setup/events"
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+	"github.com/ishida722/setup/notify"
+	"github.com/ishida722/setup/state"
+	"github.com/ishida722/setup/systemdunit"
+)
+
+func main() {
+	args := verbosityFlags(os.Args[1:])
+	command := "install"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command = args[0]
+		args = args[1:]
+	}
+
+	var err error
+	switch command {
+	case "install", "update":
+		err = runInstall(command == "update", args)
+	case "plan":
+		err = runPlanCmd(args)
+	case "apply":
+		err = runApply(args)
+	case "project":
+		err = runProject(args)
+	case "workspace":
+		err = runWorkspace(args)
+	case "provision-user":
+		err = runProvisionUser(args)
+	case "dotfiles":
+		err = runDotfiles(args)
+	case "status":
+		err = runStatus(args)
+	case "history":
+		err = runHistory(args)
+	case "state":
+		err = runState(args)
+	case "list":
+		err = runList(args)
+	case "schedule":
+		err = runSchedule(args)
+	case "cloudinit":
+		err = runCloudinit(args)
+	case "package":
+		err = runPackage(args)
+	case "remote":
+		err = runRemote(args)
+	case "export":
+		err = runExport(args)
+	case "serve":
+		err = runServe(args)
+	case "mcp":
+		err = runMCP(args)
+	case "restore":
+		err = runRestore(args)
+	case "gc":
+		err = runGC(args)
+	case "outdated":
+		err = runOutdated(args)
+	case "sbom":
+		err = runSbom(args)
+	case "licenses":
+		err = runLicenses(args)
+	default:
+		fmt.Fprintf(os.Stderr, "usage: setup [install|update|plan|apply|project|workspace|provision-user|dotfiles|status|list|history|state|schedule|cloudinit|package|remote|export|serve|mcp|restore|gc|outdated|sbom|licenses] [flags]\n")
+		os.Exit(2)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// verbosityFlags handles the global output flags any subcommand
+// accepts — -v (debug, including command traces), -q (errors only),
+// -timestamps, -trace, -prefix-output, and -events/-events-file — and
+// returns the remaining arguments.
+func verbosityFlags(args []string) []string {
+	rest := args[:0:0]
+	eventsFormat, eventsFile := "", ""
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		value := func() string {
+			if _, v, ok := strings.Cut(a, "="); ok {
+				return v
+			}
+			i++
+			if i < len(args) {
+				return args[i]
+			}
+			return ""
+		}
+		switch strings.SplitN(a, "=", 2)[0] {
+		case "-v", "--verbose":
+			logger.SetLevel(slog.LevelDebug)
+		case "-q", "--quiet":
+			logger.SetLevel(slog.LevelError)
+		case "-timestamps", "--timestamps":
+			logger.ShowTimestamps(true)
+		case "-prefix-output", "--prefix-output":
+			installer.PrefixOutput = true
+		case "-quiet-output", "--quiet-output":
+			installer.QuietOutput = true
+		case "-trace", "--trace":
+			installer.Trace = true
+		case "-events", "--events":
+			eventsFormat = value()
+		case "-events-file", "--events-file":
+			eventsFile = value()
+		case "-record", "--record":
+			if err := installer.Record(value()); err != nil {
+				logger.Error("cannot record transcript: %v", err)
+				os.Exit(2)
+			}
+		case "-replay", "--replay":
+			if err := installer.Replay(value()); err != nil {
+				logger.Error("cannot replay transcript: %v", err)
+				os.Exit(2)
+			}
+		case "-webhook", "--webhook":
+			notifier = notify.New(value())
+		case "-theme", "--theme":
+			if err := logger.SetTheme(value()); err != nil {
+				logger.Error("%v", err)
+				os.Exit(2)
+			}
+		case "-color", "--color":
+			if err := logger.SetThemeColors(strings.Split(value(), ",")); err != nil {
+				logger.Error("%v", err)
+				os.Exit(2)
+			}
+		default:
+			rest = append(rest, a)
+		}
+	}
+	if eventsFormat != "" {
+		setupEvents(eventsFormat, eventsFile)
+	}
+	return rest
+}
+
+// setupEvents wires the NDJSON lifecycle event stream. It goes to
+// stdout by default; CI wrappers usually point -events-file at a pipe
+// or file so the human log stays separate.
+func setupEvents(format, file string) {
+	if format != "ndjson" {
+		logger.Error("unsupported -events format %q (only ndjson)", format)
+		os.Exit(2)
+	}
+	w := os.Stdout
+	if file != "" {
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			logger.Error("cannot open events file: %v", err)
+			os.Exit(2)
+		}
+		w = f
+	}
+	installer.Events = events.NewEmitter(w)
+}
+
+// notifier posts run summaries when the global -webhook flag is set
+// (Slack or Discord incoming webhook URL).
+var notifier *notify.Notifier
+
+// sendNotification posts to the webhook and only logs on failure —
+// a broken webhook must not fail provisioning.
+func sendNotification(format string, a ...any) {
+	if err := notifier.Notify(format, a...); err != nil {
+		logger.Warn("webhook notification failed: %v", err)
+	}
+}
+
+// selectComponents registers the shared component flags on fs, parses
+// args, and returns the steps this invocation covers, in install
+// order. install, status and export share this path so they all see
+// the same selection; callers may register extra flags on fs first.
+func selectComponents(fs *flag.FlagSet, args []string) ([]installer.InstallCommand, error) {
+	hostname := hostnameFlags(fs)
+	ntp := fs.Bool("ntp", false, "install chrony for time synchronization")
+	ntpServers := fs.String("ntp-servers", "", "comma-separated NTP servers for chrony (implies -ntp)")
+	ufw := fs.Bool("ufw", false, "enable the UFW firewall with SSH allowed")
+	mosh := fs.Bool("mosh", false, "install mosh (opens its UDP range when -ufw is also set)")
+	nodeExporter := fs.Bool("node-exporter", false, "install Prometheus node_exporter with its systemd service")
+	wgAddress := fs.String("wireguard", "", "set up WireGuard wg0 with this VPN address (e.g. 10.8.0.2/24)")
+	wgPeer := fs.String("wireguard-peer", "", "public key of the WireGuard peer for -wireguard")
+	wgEndpoint := fs.String("wireguard-endpoint", "", "host:port of the WireGuard peer for -wireguard")
+	wgKey := fs.String("wireguard-key", "", "existing private key file for -wireguard (default: generate on this machine)")
+	wgAllowed := fs.String("wireguard-allowed-ips", "", "AllowedIPs routed through the peer (default 0.0.0.0/0)")
+	wgPort := fs.Int("wireguard-port", 0, "WireGuard listen port (default 51820)")
+	mounts := fs.String("mounts", "", `semicolon-separated NFS/CIFS shares, each "source mountpoint type [options]"`)
+	nvidia := fs.Bool("nvidia", false, "install the recommended NVIDIA driver (reboots per -reboot)")
+	cuda := fs.Bool("cuda", false, "also install the CUDA toolkit and cuDNN (implies -nvidia)")
+	databases := fs.Bool("databases", false, "install PostgreSQL and Redis for local development")
+	dbUser := fs.String("db-user", "", "PostgreSQL development role for -databases (default: login user)")
+	dbName := fs.String("db-name", "", "PostgreSQL development database for -databases (default: the role name)")
+	k8s := fs.Bool("k8s-tools", false, "install pinned kubectl, helm, k9s, and kind with fish completions")
+	terraform := fs.String("terraform", "", "install this pinned Terraform version (e.g. 1.9.5), checksum-verified")
+	opentofu := fs.String("opentofu", "", "install this pinned OpenTofu version (e.g. 1.8.2), checksum-verified")
+	aws := fs.Bool("aws", false, "install AWS CLI v2")
+	awsRegion := fs.String("aws-region", "", "default region for -aws (implies -aws)")
+	awsProfile := fs.String("aws-profile", "", "profile the -aws-region default applies to")
+	gcloud := fs.Bool("gcloud", false, "install the Google Cloud CLI")
+	gcpProject := fs.String("gcp-project", "", "default project for -gcloud (implies -gcloud)")
+	az := fs.Bool("az", false, "install the Azure CLI")
+	azLocation := fs.String("az-location", "", "default location for -az (implies -az)")
+	cloudLogin := fs.Bool("cloud-login", false, "kick off the interactive login flow for each installed cloud CLI")
+	vscode := fs.Bool("vscode", false, "install Visual Studio Code from Microsoft's apt repository")
+	vscodeExtensions := fs.String("vscode-extensions", "", "comma-separated extension IDs to install (implies -vscode)")
+	vscodeSettings := fs.String("vscode-settings", "", "settings.json to install into the VS Code profile (implies -vscode)")
+	jetbrains := fs.Bool("jetbrains", false, "install JetBrains Toolbox")
+	jetbrainsIDEs := fs.String("jetbrains-ides", "", "comma-separated IDEs for Toolbox to pre-install (implies -jetbrains)")
+	flatpakApps := fs.String("flatpak-apps", "", `semicolon-separated Flathub apps, each "app.ID [override flags]"`)
+	gnome := fs.Bool("gnome", false, "apply the default GNOME preferences (dark theme, key repeat, caps as ctrl)")
+	gsettings := fs.String("gsettings", "", `extra gsettings keys, semicolon-separated "schema key value" entries`)
+	dconfLoad := fs.String("dconf-load", "", "dconf dump file to load into / (implies -gnome settings run)")
+	japaneseFonts := fs.Bool("japanese-fonts", false, "install Noto CJK fonts with fontconfig rules preferring the JP variants")
+	clipboard := fs.Bool("clipboard", false, "install the clipboard tool matching the display server (xclip/wl-clipboard/win32yank)")
+	audio := fs.Bool("audio", false, "set up the PipeWire audio stack and Bluetooth with auto-enable")
+	tlp := fs.Bool("tlp", false, "install TLP power management on machines with a battery")
+	libvirt := fs.Bool("libvirt", false, "install the KVM/libvirt stack with virt-manager")
+	docker := fs.Bool("docker", false, "install Docker Engine from Docker's apt repository")
+	podman := fs.Bool("podman", false, "install Podman with rootless configuration and docker CLI shims")
+	aliases := fs.String("aliases", "", `comma-separated name=command aliases rendered into fish, bash, and zsh (e.g. "v=nvim,g=git")`)
+	mise := fs.Bool("mise", false, "install the mise runtime manager with fish/bash activation")
+	runnerURL := fs.String("actions-runner", "", "register a GitHub Actions runner for this repo/org URL")
+	runnerToken := fs.String("actions-runner-token", "", "registration token for -actions-runner (default: $RUNNER_TOKEN)")
+	runnerName := fs.String("actions-runner-name", "", "runner name for -actions-runner (default: the hostname)")
+	repos := fs.String("repos", "", `semicolon-separated work repos to clone, each "url [dest] [branch]"`)
+	repoAuth := fs.String("repo-auth", components.RepoAuthHTTPS, "auth for -repos clones: https, ssh, or gh")
+	precommit := fs.Bool("precommit", false, "install pre-commit and activate hooks in work repositories")
+	precommitDirs := fs.String("precommit-dirs", "", "comma-separated repo patterns for -precommit (default src/*, relative to $HOME)")
+	journald := fs.Bool("journald", false, "configure journald with persistent storage and a size cap")
+	journaldMaxUse := fs.String("journald-max-use", "200M", "SystemMaxUse value for -journald")
+	sysctlProfile := fs.String("sysctl-profile", "", "apply a sysctl profile: "+strings.Join(components.SysctlProfiles(), " or "))
+	groups := fs.String("groups", "", "comma-separated supplementary groups to add the user to")
+	homeSkeleton := fs.Bool("home-skeleton", false, "create the standard home directory layout ("+strings.Join(components.DefaultHomeDirs, ", ")+")")
+	homeDirs := fs.String("home-dirs", "", "comma-separated directories for -home-skeleton, relative to $HOME")
+	homeReadme := fs.Bool("home-readme", false, "drop README placeholders into -home-skeleton directories")
+	limits := fs.Bool("limits", false, "raise nofile/nproc limits for PAM sessions and systemd services")
+	limitNofile := fs.Int("limit-nofile", 65536, "nofile limit applied by -limits")
+	limitNproc := fs.Int("limit-nproc", 16384, "nproc limit applied by -limits")
+	logrotate := fs.Bool("logrotate", false, "install logrotate rules for setup-managed logs")
+	motd := fs.Bool("motd", false, "install the setup MOTD and disable Ubuntu's promotional parts")
+	nopasswdSudo := fs.Bool("nopasswd-sudo", false, "grant passwordless sudo to the user (asks for confirmation)")
+	shallowConfig := fs.Bool("shallow-config", false, "clone config repositories shallowly (--depth=1, no historic blobs)")
+	dotfiles := fs.String("dotfiles", "", `semicolon-separated "url dest [source]" entries replacing the default config repositories`)
+	blockSecrets := fs.Bool("block-on-secrets", false, "fail instead of warn when cloned config repos contain suspected credentials")
+	unshallowConfig := fs.Bool("unshallow-config", false, "convert shallow config repository clones to full ones")
+	yes := fs.Bool("yes", false, "answer yes to all confirmation prompts")
+	upgrade := fs.Bool("upgrade", false, "run a full apt upgrade first, rebooting and resuming if required")
+	caCerts := fs.String("ca-certs", "", "comma-separated PEM files to install as trusted CAs (TLS-inspecting proxies)")
+	aptMirror := fs.String("apt-mirror", "", "Ubuntu archive mirror URL to rewrite the apt sources to")
+	npmRegistry := fs.String("npm-registry", "", "npm registry URL for global package installs")
+	githubMirror := fs.String("github-mirror", "", "base URL replacing https://github.com/ in downloads (artifact proxy)")
+	reboot := fs.String("reboot", components.RebootAsk, "reboot policy for -upgrade and -nvidia: ask, auto, or skip")
+	channel := fs.String("channel", "", "component=channel pairs, e.g. Neovim=nightly,Node.js=current; remembered per machine")
+	manifest := fs.String("manifest", "", "YAML manifest of additional components to install alongside the selection")
+	fs.Parse(args)
+
+	// Channels resolve while the catalog is built below, so they must
+	// be in place first: what the machine already follows, then any
+	// override from this invocation (which is also what it follows
+	// from now on).
+	components.Channels = state.LoadChannels()
+	if *channel != "" {
+		parsed, err := components.ParseChannels(*channel)
+		if err != nil {
+			logger.Error("%v", err)
+			return nil, err
+		}
+		for name, ch := range parsed {
+			components.Channels[name] = ch
+			state.RecordChannel(name, ch)
+		}
+	}
+
+	var cmds []installer.InstallCommand
+	if *upgrade {
+		// On resume after the reboot the upgrade step is dropped; it
+		// already ran.
+		var resumeArgs []string
+		for _, a := range args {
+			if a != "-upgrade" && a != "--upgrade" {
+				resumeArgs = append(resumeArgs, a)
+			}
+		}
+		cmds = append(cmds, components.SystemUpgrade(*reboot, resumeArgs))
+	}
+	if hostname.Hostname() != "" {
+		cmds = append(cmds, components.Hostname(*hostname))
+	}
+	// Before anything that downloads: behind a TLS-inspecting proxy
+	// nothing else works until the CAs are trusted.
+	if *caCerts != "" {
+		cmds = append(cmds, components.CACertificates(strings.Split(*caCerts, ",")))
+	}
+	if *ntp || *ntpServers != "" {
+		var servers []string
+		if *ntpServers != "" {
+			servers = strings.Split(*ntpServers, ",")
+		}
+		cmds = append(cmds, components.Chrony(servers))
+	}
+	if *ufw {
+		cmds = append(cmds, components.UFW())
+	}
+	if *mosh {
+		cmds = append(cmds, components.Mosh(*ufw))
+	}
+	if *nodeExporter {
+		cmds = append(cmds, components.NodeExporter(*ufw))
+	}
+	if *wgAddress != "" {
+		if *wgPeer == "" || *wgEndpoint == "" {
+			err := fmt.Errorf("-wireguard requires -wireguard-peer and -wireguard-endpoint")
+			logger.Error("%v", err)
+			return nil, err
+		}
+		cmds = append(cmds, components.WireGuard(components.WireGuardConfig{
+			Address:        *wgAddress,
+			PrivateKeyFile: *wgKey,
+			PeerPublicKey:  *wgPeer,
+			PeerEndpoint:   *wgEndpoint,
+			AllowedIPs:     *wgAllowed,
+			ListenPort:     *wgPort,
+		}, *ufw))
+	}
+	if *mounts != "" {
+		parsed, err := components.ParseMounts(*mounts)
+		if err != nil {
+			logger.Error("%v", err)
+			return nil, err
+		}
+		cmds = append(cmds, components.NetworkMounts(parsed))
+	}
+	if *nvidia || *cuda {
+		// Re-running the same args after the reboot is safe: the
+		// driver check passes and every other component is idempotent.
+		cmds = append(cmds, components.Nvidia(*cuda, *reboot, args))
+	}
+	if *databases || *dbUser != "" || *dbName != "" {
+		cmds = append(cmds, components.PostgreSQL(*dbUser, *dbName), components.Redis())
+	}
+	if *journald {
+		cmds = append(cmds, components.Journald(*journaldMaxUse))
+	}
+	if *logrotate {
+		cmds = append(cmds, components.Logrotate())
+	}
+	if *motd {
+		cmds = append(cmds, components.MOTD())
+	}
+	if *nopasswdSudo {
+		cmds = append(cmds, components.PasswordlessSudo(*yes))
+	}
+	if *homeSkeleton || *homeDirs != "" {
+		var dirs []string
+		if *homeDirs != "" {
+			dirs = strings.Split(*homeDirs, ",")
+		}
+		cmds = append(cmds, components.HomeSkeleton(dirs, *homeReadme))
+	}
+	if *limits {
+		cmds = append(cmds, components.Limits(*limitNofile, *limitNproc))
+	}
+	if *sysctlProfile != "" {
+		cmd, err := components.Sysctl(*sysctlProfile)
+		if err != nil {
+			logger.Error("%v", err)
+			return nil, err
+		}
+		cmds = append(cmds, cmd)
+	}
+	components.ShallowClones = *shallowConfig
+	components.SecretScanBlock = *blockSecrets
+	if *dotfiles != "" {
+		parsed, err := components.ParseDotfiles(*dotfiles)
+		if err != nil {
+			logger.Error("%v", err)
+			return nil, err
+		}
+		components.CustomDotfiles = parsed
+	}
+	cmds = append(cmds, components.Catalog()...)
+	// After the catalog: the fish completions land in ~/.config/fish,
+	// which must be cloned (or skipped) by the config repos first.
+	if *k8s {
+		cmds = append(cmds, components.K8sTools()...)
+	}
+	if *aliases != "" {
+		parsed, err := components.ParseAliases(*aliases)
+		if err != nil {
+			logger.Error("%v", err)
+			return nil, err
+		}
+		cmds = append(cmds, components.ShellAliases(parsed))
+	}
+	if *mise {
+		// After the catalog: the fish activation lands in
+		// ~/.config/fish, which the config repos must clone first.
+		cmds = append(cmds, components.Mise())
+	}
+	if *runnerURL != "" {
+		token := *runnerToken
+		if token == "" {
+			token = os.Getenv("RUNNER_TOKEN")
+		}
+		if token == "" {
+			err := fmt.Errorf("-actions-runner needs a registration token (-actions-runner-token or $RUNNER_TOKEN)")
+			logger.Error("%v", err)
+			return nil, err
+		}
+		cmds = append(cmds, components.ActionsRunner(*runnerURL, token, *runnerName))
+	}
+	if *repos != "" {
+		parsed, err := components.ParseWorkRepos(*repos)
+		if err != nil {
+			logger.Error("%v", err)
+			return nil, err
+		}
+		cmds = append(cmds, components.WorkRepos(parsed, *repoAuth))
+	}
+	// After -repos so freshly bootstrapped clones get hooks on the
+	// next run at the latest.
+	if *precommit || *precommitDirs != "" {
+		var dirs []string
+		if *precommitDirs != "" {
+			dirs = strings.Split(*precommitDirs, ",")
+		}
+		cmds = append(cmds, components.PreCommit(dirs))
+	}
+	if *terraform != "" {
+		cmds = append(cmds, components.Terraform(*terraform))
+	}
+	if *opentofu != "" {
+		cmds = append(cmds, components.OpenTofu(*opentofu))
+	}
+	if *aws || *awsRegion != "" {
+		cmds = append(cmds, components.AWSCLI(*awsRegion, *awsProfile, *cloudLogin))
+	}
+	if *gcloud || *gcpProject != "" {
+		cmds = append(cmds, components.GCloud(*gcpProject, *cloudLogin))
+	}
+	if *az || *azLocation != "" {
+		cmds = append(cmds, components.AzureCLI(*azLocation, *cloudLogin))
+	}
+	if *vscode || *vscodeExtensions != "" || *vscodeSettings != "" {
+		var exts []string
+		if *vscodeExtensions != "" {
+			exts = strings.Split(*vscodeExtensions, ",")
+		}
+		cmds = append(cmds, components.VSCode(exts, *vscodeSettings))
+	}
+	if *jetbrains || *jetbrainsIDEs != "" {
+		var ides []string
+		if *jetbrainsIDEs != "" {
+			ides = strings.Split(*jetbrainsIDEs, ",")
+		}
+		cmds = append(cmds, components.JetBrainsToolbox(ides))
+	}
+	if *flatpakApps != "" {
+		apps, err := components.ParseFlatpakApps(*flatpakApps)
+		if err != nil {
+			logger.Error("%v", err)
+			return nil, err
+		}
+		cmds = append(cmds, components.Flatpak(apps))
+	}
+	if *gnome || *gsettings != "" || *dconfLoad != "" {
+		var settings []components.GSetting
+		if *gnome {
+			settings = components.DefaultGSettings
+		}
+		if *gsettings != "" {
+			extra, err := components.ParseGSettings(*gsettings)
+			if err != nil {
+				logger.Error("%v", err)
+				return nil, err
+			}
+			settings = append(settings, extra...)
+		}
+		cmds = append(cmds, components.GnomeSettings(settings, *dconfLoad))
+	}
+	if *japaneseFonts {
+		cmds = append(cmds, components.JapaneseFonts())
+	}
+	if *clipboard {
+		cmds = append(cmds, components.Clipboard())
+	}
+	if *audio {
+		cmds = append(cmds, components.PipeWire(), components.Bluetooth())
+	}
+	if *tlp {
+		cmds = append(cmds, components.TLP())
+	}
+	if *libvirt {
+		cmds = append(cmds, components.Libvirt())
+	}
+	if *docker {
+		cmds = append(cmds, components.Docker())
+	}
+	if *podman {
+		cmds = append(cmds, components.Podman())
+	}
+	if *unshallowConfig {
+		cmds = append(cmds, components.UnshallowConfigRepos())
+	}
+	// Last so groups created by earlier components already exist.
+	if *groups != "" {
+		cmds = append(cmds, components.UserGroups(strings.Split(*groups, ",")))
+	}
+	if *manifest != "" {
+		loaded, err := installer.LoadManifest(*manifest)
+		if err != nil {
+			logger.Error("%v", err)
+			return nil, err
+		}
+		cmds = append(cmds, loaded...)
+	}
+	// Positional names narrow the run to just those components, e.g.
+	// `setup install Neovim Lazygit`.
+	if names := fs.Args(); len(names) > 0 {
+		filtered, err := filterComponents(cmds, names)
+		if err != nil {
+			logger.Error("%v", err)
+			return nil, err
+		}
+		cmds = filtered
+	}
+	if err := installer.CheckConflicts(cmds); err != nil {
+		logger.Error("%v", err)
+		return nil, err
+	}
+	mirrors := components.Mirrors{Apt: *aptMirror, Npm: *npmRegistry, GitHub: *githubMirror}
+	return components.ApplyMirrors(mirrors, components.BatchNpmGlobals(cmds)), nil
+}
+
+// filterComponents keeps only the named components, in their original
+// order. Names match case-insensitively; an unknown one is an error
+// rather than a silently empty run.
+func filterComponents(cmds []installer.InstallCommand, names []string) ([]installer.InstallCommand, error) {
+	wanted := map[string]bool{}
+	for _, n := range names {
+		wanted[strings.ToLower(n)] = false
+	}
+	var out []installer.InstallCommand
+	for _, c := range cmds {
+		key := strings.ToLower(c.Name)
+		if _, ok := wanted[key]; ok {
+			wanted[key] = true
+			out = append(out, c)
+		}
+	}
+	for _, n := range names {
+		if !wanted[strings.ToLower(n)] {
+			return nil, fmt.Errorf("unknown component %q (see `setup list`, and note flag-gated components need their flag)", n)
+		}
+	}
+	return out, nil
+}
+
+// runList prints the built-in catalog. -names emits bare names for
+// shell completion without building a single component; the table form
+// builds definitions but runs no check commands, so both stay instant
+// however large the catalog gets.
+func runList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	names := fs.Bool("names", false, "print bare component names, one per line")
+	fs.Parse(args)
+
+	entries := components.CatalogEntries()
+	if *names {
+		for _, e := range entries {
+			fmt.Println(e.Name)
+		}
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "COMPONENT\tDOMAINS")
+	for _, e := range entries {
+		c := e.Build()
+		fmt.Fprintf(w, "%s\t%s\n", c.Name, strings.Join(c.Domains, ", "))
+	}
+	return w.Flush()
+}
+
+// runInstall provisions the machine. As update it additionally
+// upgrades GitHub-release components that are behind upstream, after
+// showing the release notes between the versions.
+func runInstall(update bool, args []string) error {
+	// -sandbox reroutes the whole run into a container; strip the
+	// sandbox flags and pass the rest through unchanged.
+	sandbox := false
+	image := "ubuntu:24.04"
+	rest := args[:0:0]
+	for i := 0; i < len(args); i++ {
+		switch strings.SplitN(args[i], "=", 2)[0] {
+		case "-sandbox", "--sandbox":
+			sandbox = true
+		case "-sandbox-image", "--sandbox-image":
+			if _, v, ok := strings.Cut(args[i], "="); ok {
+				image = v
+			} else if i+1 < len(args) {
+				i++
+				image = args[i]
+			}
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	if sandbox {
+		return runSandbox(image, rest)
+	}
+	args = rest
+
+	fs := flag.NewFlagSet("install", flag.ExitOnError)
+	metricsFile := fs.String("metrics-file", "", "write per-component run metrics in Prometheus textfile format")
+	telemetryEndpoint := fs.String("telemetry-endpoint", "", "opt in to posting anonymized per-component outcomes to this URL after the run")
+	skipPreflight := fs.Bool("skip-preflight", false, "skip the pre-flight environment checks")
+	errorReport := fs.Bool("error-report", false, "on failure, bundle a tar.gz report to attach to an issue")
+	tui := fs.Bool("tui", false, "show a live full-screen dashboard instead of the scrolling log")
+	dryRun := fs.Bool("dry-run", false, "print the commands that would run without executing anything")
+	parallel := fs.Int("parallel", 0, "run independent components concurrently with this many in flight (apt steps stay serialized)")
+	licensePolicy := fs.String("license-policy", "", "file listing disallowed licenses; matching components block the run")
+	cmds, err := selectComponents(fs, args)
+	if err != nil {
+		return err
+	}
+	// -yes is registered by selectComponents (it already gates the
+	// confirmation prompts); for update it additionally suppresses the
+	// release-notes prompt below.
+	yes := fs.Lookup("yes").Value.String() == "true"
+	installer.DryRun = *dryRun
+	if *licensePolicy != "" {
+		if err := checkLicensePolicy(*licensePolicy, cmds); err != nil {
+			logger.Error("aborting before any changes: %v", err)
+			return err
+		}
+	}
+	release, err := acquireRunLock()
+	if err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+	defer release()
+	if !*skipPreflight {
+		if problems := installer.Preflight(cmds); len(problems) > 0 {
+			for _, p := range problems {
+				logger.Error("pre-flight: %s", p)
+			}
+			logger.Error("aborting before any changes (-skip-preflight to override)")
+			return fmt.Errorf("%d pre-flight checks failed", len(problems))
+		}
+	}
+	var transcript *logger.BoundedBuffer
+	if *errorReport {
+		var restore func()
+		transcript, restore = captureTranscript()
+		defer restore()
+	}
+	stop := trapSignals()
+	defer stop()
+	// A dry run must not prompt for sudo, snapshot anything, or ping
+	// webhooks; it only reads the machine and prints.
+	if !*dryRun {
+		stopSudo := installer.KeepSudoAlive()
+		defer stopSudo()
+		if name, serr := captureEnvSnapshot(cmds); serr != nil {
+			logger.Warn("could not snapshot environment: %v", serr)
+		} else {
+			logger.Debug("environment snapshot %s taken (setup restore %s)", name, name)
+		}
+	}
+	host, _ := os.Hostname()
+	installer.Events.Emit(events.Event{Event: "run_started"})
+	if !*dryRun {
+		sendNotification("setup: run started on %s (%d components)", host, len(cmds))
+	}
+	run := installer.RunAllResults
+	if *parallel > 1 {
+		run = func(cmds []installer.InstallCommand) ([]installer.Result, error) {
+			return installer.RunParallel(cmds, *parallel)
+		}
+	}
+	if update {
+		run = func(cmds []installer.InstallCommand) ([]installer.Result, error) {
+			return installer.RunSteps(upgradeSteps(cmds, yes))
+		}
+	}
+	if *tui {
+		run = runTUI
+	}
+	results, err := run(cmds)
+	printSummary(results)
+	if *dryRun {
+		if err != nil {
+			return err
+		}
+		logger.Success("dry run complete, nothing was changed")
+		return nil
+	}
+	recordRun(args, results, err)
+	if *metricsFile != "" {
+		writeMetrics(*metricsFile, results)
+	}
+	if *telemetryEndpoint != "" {
+		sendTelemetry(*telemetryEndpoint, results)
+	}
+	if err != nil {
+		installer.Events.Emit(events.Event{Event: "run_finished", Error: err.Error()})
+		sendNotification("setup: run FAILED on %s: %v", host, err)
+		logger.Error("setup finished with errors")
+		// An interrupted run always leaves a report behind so the
+		// partial state is documented even without -error-report.
+		if *errorReport || errors.Is(err, installer.ErrInterrupted) {
+			var captured []byte
+			if transcript != nil {
+				captured = transcript.Bytes()
+			}
+			if path, rerr := writeErrorReport(captured, results, args); rerr != nil {
+				logger.Warn("could not write error report: %v", rerr)
+			} else {
+				logger.Info("error report written to %s", path)
+			}
+		}
+		return err
+	}
+	installer.Events.Emit(events.Event{Event: "run_finished"})
+	sendNotification("setup: run succeeded on %s", host)
+	logger.Success("setup complete")
+	return nil
+}
+
+// trapSignals stops the run cleanly on SIGINT/SIGTERM: the current
+// command's process group is terminated, registered cleanups run, and
+// the run ends at the next component boundary with a partial report. A
+// second signal exits immediately.
+func trapSignals() (stop func()) {
+	ch := make(chan os.Signal, 2)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig, ok := <-ch
+		if !ok {
+			return
+		}
+		logger.Warn("%v received, finishing current step and cleaning up (repeat to force quit)", sig)
+		installer.Interrupt()
+		if _, ok := <-ch; ok {
+			os.Exit(130)
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(ch)
+	}
+}
+
+// printSummary renders the end-of-run table: component, action taken,
+// detected version, and duration.
+func printSummary(results []installer.Result) {
+	if len(results) == 0 {
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "\nCOMPONENT\tACTION\tVERSION\tDURATION")
+	for _, r := range results {
+		duration := ""
+		if r.Duration > 0 {
+			duration = r.Duration.String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Name, r.Action, r.Version, duration)
+	}
+	w.Flush()
+	fmt.Println()
+}
+
+// runStatus reports which selected components are installed and exits
+// non-zero when the machine has drifted from the expected state.
+func runStatus(args []string) error {
+	cmds, err := selectComponents(flag.NewFlagSet("status", flag.ExitOnError), args)
+	if err != nil {
+		return err
+	}
+	missing := 0
+	for _, c := range cmds {
+		if !c.HasChecks() {
+			continue
+		}
+		if c.Installed() {
+			if problems := c.VerifyBinaries(); len(problems) > 0 {
+				for _, p := range problems {
+					logger.Error("%s: integrity: %s", c.Name, p)
+				}
+				missing++
+				continue
+			}
+			logger.Success("%s: installed", c.Name)
+		} else {
+			drifted := false
+			for _, r := range c.Resources {
+				if r.InSync() {
+					continue
+				}
+				logger.Error("%s: %s: want %s, have %s", c.Name, r.ID(), r.Desired(), r.Current())
+				drifted = true
+			}
+			if !drifted {
+				logger.Error("%s: missing", c.Name)
+			}
+			missing++
+		}
+	}
+	writeStatusFile(missing)
+	if missing > 0 {
+		host, _ := os.Hostname()
+		sendNotification("setup: drift detected on %s: %d components missing", host, missing)
+		return fmt.Errorf("%d components missing", missing)
+	}
+	return nil
+}
+
+// writeStatusFile records the status result for the MOTD. Failures are
+// ignored: the file is only writable when status runs as root (e.g.
+// from the setup-check timer).
+func writeStatusFile(missing int) {
+	result := "ok"
+	if missing > 0 {
+		result = fmt.Sprintf("%d components missing", missing)
+	}
+	line := fmt.Sprintf("%s (%s)\n", result, time.Now().Format("2006-01-02 15:04"))
+	if err := os.MkdirAll(filepath.Dir(components.StatusFile), 0o755); err != nil {
+		return
+	}
+	_ = os.WriteFile(components.StatusFile, []byte(line), 0o644)
+}
+
+// runSchedule installs a systemd timer that re-runs setup periodically
+// so drift is noticed instead of machines quietly rotting.
+func runSchedule(args []string) error {
+	fs := flag.NewFlagSet("schedule", flag.ExitOnError)
+	interval := fs.String("interval", "daily", "systemd OnCalendar expression for the timer")
+	subcommand := fs.String("command", "status", "setup subcommand the timer runs (status or update)")
+	fs.Parse(args)
+
+	if *subcommand != "status" && *subcommand != "update" {
+		logger.Error("schedule: -command must be status or update")
+		return fmt.Errorf("invalid -command %q", *subcommand)
+	}
+	self, err := os.Executable()
+	if err != nil {
+		logger.Error("schedule: cannot locate setup binary: %v", err)
+		return err
+	}
+
+	service, timer := scheduleUnits(self, *subcommand, *interval)
+	return installer.RunAll([]installer.InstallCommand{
+		service.InstallCommand(),
+		timer.InstallCommand(),
+	})
+}
+
+// scheduleUnits builds the setup-check service/timer pair running
+// `binary subcommand` on the given OnCalendar interval.
+func scheduleUnits(binary, subcommand, interval string) (service, timer systemdunit.Unit) {
+	service = systemdunit.Unit{
+		Name: "setup-check.service",
+		Content: fmt.Sprintf(`[Unit]
+Description=Periodic setup %s run
+
+[Service]
+Type=oneshot
+ExecStart=%s %s
+`, subcommand, binary, subcommand),
+	}
+	timer = systemdunit.Unit{
+		Name: "setup-check.timer",
+		Content: fmt.Sprintf(`[Unit]
+Description=Periodic setup %s timer
+
+[Timer]
+OnCalendar=%s
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`, subcommand, interval),
+		Enable: true,
+	}
+	return service, timer
+}
+
+// runCloudinit emits a cloud-config user-data document that downloads
+// the setup binary and provisions the instance on first boot, so new
+// cloud instances self-provision without any SSH session.
+func runCloudinit(args []string) error {
+	fs := flag.NewFlagSet("cloudinit", flag.ExitOnError)
+	url := fs.String("url", "https://github.com/ishida722/setup/releases/latest/download/setup-linux-amd64",
+		"download URL for the setup binary")
+	installArgs := fs.String("args", "", "flags passed to `setup install` on first boot")
+	fs.Parse(args)
+
+	install := "/usr/local/bin/setup install -yes"
+	if *installArgs != "" {
+		install += " " + *installArgs
+	}
+	fmt.Printf(`#cloud-config
+package_update: true
+packages:
+  - curl
+runcmd:
+  - curl -fsSL %s -o /usr/local/bin/setup
+  - chmod +x /usr/local/bin/setup
+  - %s
+`, *url, install)
+	return nil
+}
+
+// hostnameFlags registers the hostname step's flags on fs.
+func hostnameFlags(fs *flag.FlagSet) *components.HostnameOptions {
+	o := &components.HostnameOptions{}
+	fs.StringVar(&o.Name, "hostname", "", "hostname to set on this machine")
+	fs.StringVar(&o.Template, "hostname-template", "", "hostname template with {role}/{location}/{index} placeholders")
+	fs.StringVar(&o.Role, "role", "", "value for {role} in the hostname template")
+	fs.StringVar(&o.Location, "location", "", "value for {location} in the hostname template")
+	fs.IntVar(&o.Index, "index", 0, "value for {index} in the hostname template")
+	return o
+}
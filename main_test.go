@@ -0,0 +1,21 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// runInstall registers its own flags and then the shared component
+// flags via selectComponents on the same FlagSet; a name registered
+// twice panics at registration time, before anything is parsed. Every
+// provisioning path funnels through this parse — sandbox, remote, and
+// cloudinit all shell out to `setup install -yes`, and workspace apply
+// calls runInstall directly — so exercise it end to end up to component
+// selection, where the bogus positional argument stops the run.
+func TestRunInstallParsesFlags(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	err := runInstall(false, []string{"-dry-run", "-yes", "no-such-component"})
+	if err == nil || !strings.Contains(err.Error(), "unknown component") {
+		t.Fatalf("err = %v, want unknown-component error", err)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ishida722/setup/components"
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+)
+
+// runMCP serves the Model Context Protocol over stdio, exposing the
+// catalog as tools so the Claude Code this tool installs can then
+// manage the machine's setup conversationally. The protocol is plain
+// JSON-RPC 2.0, one message per line, which the stdlib covers — no
+// SDK dependency needed for four tools.
+func runMCP(args []string) error {
+	// stdout is the protocol channel; everything human-readable must
+	// go to stderr.
+	logger.Default = logger.NewConsole(os.Stderr, os.Stderr)
+
+	dec := json.NewDecoder(bufio.NewReader(os.Stdin))
+	enc := json.NewEncoder(os.Stdout)
+	for {
+		var req mcpRequest
+		if err := dec.Decode(&req); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		resp := handleMCP(req)
+		if resp == nil {
+			continue // notification
+		}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+}
+
+type mcpRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type mcpResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *mcpError       `json:"error,omitempty"`
+}
+
+type mcpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// handleMCP dispatches one message; notifications (no id) return nil.
+func handleMCP(req mcpRequest) *mcpResponse {
+	if req.ID == nil {
+		return nil
+	}
+	resp := &mcpResponse{JSONRPC: "2.0", ID: req.ID}
+	switch req.Method {
+	case "initialize":
+		resp.Result = map[string]any{
+			"protocolVersion": "2024-11-05",
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "setup", "version": version},
+		}
+	case "tools/list":
+		resp.Result = map[string]any{"tools": mcpTools()}
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			resp.Error = &mcpError{Code: -32602, Message: err.Error()}
+			break
+		}
+		text, err := callMCPTool(params.Name, params.Arguments)
+		if err != nil {
+			resp.Result = mcpText(err.Error(), true)
+			break
+		}
+		resp.Result = mcpText(text, false)
+	case "ping":
+		resp.Result = map[string]any{}
+	default:
+		resp.Error = &mcpError{Code: -32601, Message: "unknown method " + req.Method}
+	}
+	return resp
+}
+
+// mcpTools describes the exposed tools in MCP's tool schema.
+func mcpTools() []map[string]any {
+	noArgs := map[string]any{"type": "object", "properties": map[string]any{}}
+	nameArg := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name": map[string]any{"type": "string", "description": "component name from list_components"},
+		},
+		"required": []string{"name"},
+	}
+	return []map[string]any{
+		{"name": "list_components", "description": "List the components setup can install.", "inputSchema": noArgs},
+		{"name": "check_status", "description": "Report which components are installed, with versions.", "inputSchema": noArgs},
+		{"name": "plan", "description": "Show what a full run would install and what it would skip, without changing anything.", "inputSchema": noArgs},
+		{"name": "install_component", "description": "Install one component by name and return the outcome.", "inputSchema": nameArg},
+	}
+}
+
+// callMCPTool executes a tool and returns its text result.
+func callMCPTool(name string, args json.RawMessage) (string, error) {
+	switch name {
+	case "list_components":
+		var b strings.Builder
+		for _, e := range components.CatalogEntries() {
+			fmt.Fprintln(&b, e.Name)
+		}
+		return b.String(), nil
+	case "check_status":
+		var b strings.Builder
+		for _, c := range components.Catalog() {
+			if !c.HasChecks() {
+				continue
+			}
+			if c.Installed() {
+				if v := c.Version(); v != "" {
+					fmt.Fprintf(&b, "%s: installed (%s)\n", c.Name, v)
+				} else {
+					fmt.Fprintf(&b, "%s: installed\n", c.Name)
+				}
+			} else {
+				fmt.Fprintf(&b, "%s: missing\n", c.Name)
+			}
+		}
+		return b.String(), nil
+	case "plan":
+		var b strings.Builder
+		for _, s := range installer.PlanConcurrent(components.Catalog()) {
+			fmt.Fprintf(&b, "%-10s %s\n", s.Action, s.Command.Name)
+		}
+		return b.String(), nil
+	case "install_component":
+		var params struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(args, &params); err != nil {
+			return "", err
+		}
+		return mcpInstall(params.Name)
+	default:
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+}
+
+// mcpInstall runs one component with its output captured, so the
+// assistant gets the tail of the log alongside the outcome.
+func mcpInstall(name string) (string, error) {
+	cmds, err := catalogByName([]string{name})
+	if err != nil {
+		return "", err
+	}
+	buf := logger.NewBoundedBuffer(16 << 10)
+	prevOut, prevErr := installer.Output, installer.ErrOutput
+	prevLogger := logger.Default
+	installer.Output, installer.ErrOutput = buf, buf
+	logger.Default = logger.NewConsole(buf, buf)
+	results, err := installer.RunAllResults(cmds)
+	installer.Output, installer.ErrOutput = prevOut, prevErr
+	logger.Default = prevLogger
+
+	var b strings.Builder
+	for _, r := range results {
+		fmt.Fprintf(&b, "%s: %s", r.Name, r.Action)
+		if r.Version != "" {
+			fmt.Fprintf(&b, " (%s)", r.Version)
+		}
+		if r.Err != nil {
+			fmt.Fprintf(&b, ": %v", r.Err)
+		}
+		fmt.Fprintln(&b)
+	}
+	if err != nil {
+		fmt.Fprintf(&b, "\nlog:\n%s", buf.String())
+		return b.String(), nil // tool-level failure, not protocol-level
+	}
+	return b.String(), nil
+}
+
+// mcpText wraps text in MCP's content result shape.
+func mcpText(text string, isError bool) map[string]any {
+	result := map[string]any{
+		"content": []map[string]any{{"type": "text", "text": text}},
+	}
+	if isError {
+		result["isError"] = true
+	}
+	return result
+}
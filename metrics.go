@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+)
+
+// writeMetrics renders the run results in Prometheus textfile format
+// (for node_exporter's textfile collector), so fleet provisioning
+// durations and failure rates can be tracked over time. The file is
+// written atomically via a temp file, as the collector may scrape at
+// any moment.
+func writeMetrics(path string, results []installer.Result) {
+	var b strings.Builder
+	b.WriteString("# HELP setup_component_duration_seconds Time spent installing the component in the last run.\n")
+	b.WriteString("# TYPE setup_component_duration_seconds gauge\n")
+	for _, r := range results {
+		if r.Action == "skipped" {
+			continue
+		}
+		fmt.Fprintf(&b, "setup_component_duration_seconds{component=%q} %g\n", r.Name, r.Duration.Seconds())
+	}
+	b.WriteString("# HELP setup_component_success Whether the component installed (1) or failed (0) in the last run; skipped components are 1.\n")
+	b.WriteString("# TYPE setup_component_success gauge\n")
+	for _, r := range results {
+		success := 1
+		if r.Action == "failed" {
+			success = 0
+		}
+		fmt.Fprintf(&b, "setup_component_success{component=%q} %d\n", r.Name, success)
+	}
+	b.WriteString("# HELP setup_last_run_timestamp_seconds Unix time of the last setup run.\n")
+	b.WriteString("# TYPE setup_last_run_timestamp_seconds gauge\n")
+	fmt.Fprintf(&b, "setup_last_run_timestamp_seconds %d\n", time.Now().Unix())
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0o644); err != nil {
+		logger.Warn("cannot write metrics file: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		logger.Warn("cannot write metrics file: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+// Package notify posts run summaries to a chat webhook, for
+// provisioning that happens unattended (cron, cloud-init, timers).
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Notifier posts messages to a Slack- or Discord-compatible incoming
+// webhook. A nil Notifier is silently inert so call sites need no
+// guards.
+type Notifier struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+// New returns a Notifier for the webhook URL, or nil when the URL is
+// empty (notifications disabled).
+func New(url string) *Notifier {
+	if url == "" {
+		return nil
+	}
+	return &Notifier{
+		WebhookURL: url,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify posts one message. The payload carries both the Slack (text)
+// and Discord (content) field so either webhook type accepts it.
+func (n *Notifier) Notify(format string, a ...any) error {
+	if n == nil {
+		return nil
+	}
+	msg := fmt.Sprintf(format, a...)
+	body, err := json.Marshal(map[string]string{
+		"text":    msg,
+		"content": msg,
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := n.Client.Post(n.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
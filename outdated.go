@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/ishida722/setup/logger"
+)
+
+// runOutdated compares installed versions of the selected components
+// against the latest available upstream (apt candidate, npm registry,
+// GitHub release) and lists what is behind, changing nothing. Exits
+// non-zero when something is outdated, so it can gate an update.
+func runOutdated(args []string) error {
+	cmds, err := selectComponents(flag.NewFlagSet("outdated", flag.ExitOnError), args)
+	if err != nil {
+		return err
+	}
+
+	type row struct {
+		name, installed, latest string
+		outdated                bool
+	}
+	rows := make([]row, len(cmds))
+	// The latest-version probes are network calls; run them in
+	// parallel like the pre-flight domain checks.
+	var wg sync.WaitGroup
+	for i, c := range cmds {
+		if c.LatestVersion == nil || !c.HasChecks() || !c.Installed() {
+			continue
+		}
+		i, c := i, c
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := row{name: c.Name, installed: c.Version()}
+			latest, err := c.LatestVersion()
+			if err != nil {
+				logger.Debug("%s: latest version: %v", c.Name, err)
+				r.latest = "?"
+			} else {
+				r.latest = latest
+				have, want := versionToken(r.installed), versionToken(latest)
+				r.outdated = have != "" && want != "" && have != want
+			}
+			rows[i] = r
+		}()
+	}
+	wg.Wait()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "COMPONENT\tINSTALLED\tLATEST")
+	outdated := 0
+	for _, r := range rows {
+		if r.name == "" {
+			continue
+		}
+		marker := ""
+		if r.outdated {
+			marker = "  (outdated)"
+			outdated++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s%s\n", r.name, r.installed, r.latest, marker)
+	}
+	w.Flush()
+	if outdated > 0 {
+		return fmt.Errorf("%d components outdated", outdated)
+	}
+	return nil
+}
+
+// versionRe extracts the dotted version number from strings like
+// "NVIM v0.11.0", "v0.45.2", or "1.2.3-1ubuntu1".
+var versionRe = regexp.MustCompile(`[0-9]+(?:\.[0-9]+)+`)
+
+func versionToken(s string) string {
+	return versionRe.FindString(s)
+}
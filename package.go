@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/ishida722/setup/logger"
+)
+
+// version is overridden at release time via
+// -ldflags "-X main.version=...".
+var version = "0.0.0-dev"
+
+// defaultProfile ships with the .deb so fleet machines have a starting
+// point for shared install flags.
+const defaultProfile = `# Default flags for setup install, one invocation's worth per line of
+# the fleet's choosing. Installed by the setup package; edit to taste.
+#
+# Example:
+# -journald -motd -logrotate
+`
+
+// runPackage builds distributable artifacts. Currently only
+// `setup package deb` is supported; it stages the running binary, the
+// default profile, and the setup-check timer units, then calls
+// dpkg-deb so the result can be pushed through an internal apt repo.
+func runPackage(args []string) error {
+	if len(args) < 1 || args[0] != "deb" {
+		fmt.Fprintf(os.Stderr, "usage: setup package deb [flags]\n")
+		return fmt.Errorf("unknown package format")
+	}
+	fs := flag.NewFlagSet("package deb", flag.ExitOnError)
+	out := fs.String("o", fmt.Sprintf("setup_%s_%s.deb", version, debArch()), "output .deb path")
+	fs.Parse(args[1:])
+
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate setup binary: %w", err)
+	}
+	stage, err := os.MkdirTemp("", "setup-deb-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stage)
+
+	control := fmt.Sprintf(`Package: setup
+Version: %s
+Architecture: %s
+Maintainer: ishida722
+Section: admin
+Priority: optional
+Description: Ubuntu development environment setup tool
+ Go implementation of setup.sh / playbook.yml. Installs the setup
+ binary, a default profile, and the setup-check drift timer.
+`, version, debArch())
+
+	service, timer := scheduleUnits("/usr/local/bin/setup", "status", "daily")
+	files := map[string]struct {
+		content string
+		mode    os.FileMode
+	}{
+		"DEBIAN/control":                         {control, 0o644},
+		"etc/setup/profile":                      {defaultProfile, 0o644},
+		"lib/systemd/system/setup-check.service": {service.Content, 0o644},
+		"lib/systemd/system/setup-check.timer":   {timer.Content, 0o644},
+	}
+	for path, f := range files {
+		full := filepath.Join(stage, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(full, []byte(f.content), f.mode); err != nil {
+			return err
+		}
+	}
+	binDir := filepath.Join(stage, "usr/local/bin")
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		return err
+	}
+	bin, err := os.ReadFile(self)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(binDir, "setup"), bin, 0o755); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("dpkg-deb", "--build", "--root-owner-group", stage, *out)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		logger.Error("dpkg-deb failed: %v", err)
+		return err
+	}
+	logger.Success("built %s", *out)
+	return nil
+}
+
+// debArch maps GOARCH to the Debian architecture name.
+func debArch() string {
+	switch runtime.GOARCH {
+	case "amd64":
+		return "amd64"
+	case "arm64":
+		return "arm64"
+	default:
+		return runtime.GOARCH
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+)
+
+// PlanFile is the artifact produced by `setup plan` and consumed by
+// `setup apply`: the component selection it was made from and the
+// action decided for each component. Saving the selection args lets
+// apply rebuild the exact same component list.
+type PlanFile struct {
+	Host  string     `json:"host"`
+	Time  time.Time  `json:"time"`
+	Args  []string   `json:"args"`
+	Steps []PlanStep `json:"steps"`
+}
+
+// PlanStep is one planned action.
+type PlanStep struct {
+	Name   string `json:"name"`
+	Action string `json:"action"` // "install" or "skip"
+}
+
+// runPlanCmd evaluates every component's checks concurrently and
+// writes the resulting plan, separating the slow evaluation from
+// execution:
+//
+//	setup plan [component flags] -out plan.json
+//	setup apply plan.json
+func runPlanCmd(args []string) error {
+	// -out is stripped before selection so the saved Args replay
+	// cleanly through selectComponents in apply.
+	out := ""
+	rest := args[:0:0]
+	for i := 0; i < len(args); i++ {
+		switch strings.SplitN(args[i], "=", 2)[0] {
+		case "-out", "--out":
+			if _, v, ok := strings.Cut(args[i], "="); ok {
+				out = v
+			} else if i+1 < len(args) {
+				i++
+				out = args[i]
+			}
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	args = rest
+
+	cmds, err := selectComponents(flag.NewFlagSet("plan", flag.ExitOnError), args)
+	if err != nil {
+		return err
+	}
+	steps := installer.PlanConcurrent(cmds)
+	host, _ := os.Hostname()
+	plan := PlanFile{Host: host, Time: time.Now(), Args: args}
+	installs := 0
+	for _, s := range steps {
+		plan.Steps = append(plan.Steps, PlanStep{Name: s.Command.Name, Action: s.Action})
+		if s.Action == "install" {
+			installs++
+		}
+	}
+
+	w := os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			logger.Error("%v", err)
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(plan); err != nil {
+		return err
+	}
+	logger.Info("plan: %d to install, %d already present", installs, len(steps)-installs)
+	return nil
+}
+
+// runApply executes a plan file exactly as recorded: components the
+// plan marked "skip" are skipped without re-checking, the rest are
+// installed in plan order.
+func runApply(args []string) error {
+	if len(args) != 1 {
+		logger.Error("usage: setup apply <plan.json>")
+		return fmt.Errorf("apply needs a plan file")
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+	var plan PlanFile
+	if err := json.Unmarshal(data, &plan); err != nil {
+		logger.Error("%s: %v", args[0], err)
+		return err
+	}
+	cmds, err := selectComponents(flag.NewFlagSet("apply", flag.ExitOnError), plan.Args)
+	if err != nil {
+		return err
+	}
+	byName := map[string]installer.InstallCommand{}
+	for _, c := range cmds {
+		byName[c.Name] = c
+	}
+	steps := make([]installer.Step, 0, len(plan.Steps))
+	for _, s := range plan.Steps {
+		c, ok := byName[s.Name]
+		if !ok {
+			logger.Error("plan references unknown component %q (made with a different version?)", s.Name)
+			return fmt.Errorf("unknown component %q in plan", s.Name)
+		}
+		steps = append(steps, installer.Step{Command: c, Action: s.Action})
+	}
+
+	release, err := acquireRunLock()
+	if err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+	defer release()
+	stop := trapSignals()
+	defer stop()
+	stopSudo := installer.KeepSudoAlive()
+	defer stopSudo()
+	logger.Info("applying plan from %s (%s)", plan.Host, plan.Time.Format("2006-01-02 15:04"))
+	results, err := installer.RunSteps(steps)
+	printSummary(results)
+	recordRun(plan.Args, results, err)
+	if err != nil {
+		logger.Error("setup finished with errors")
+		return err
+	}
+	logger.Success("setup complete")
+	return nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+	"github.com/ishida722/setup/resources"
+)
+
+// projectManifestName is the repo-local manifest `setup project` looks
+// for, walking up from the working directory like git does.
+const projectManifestName = ".setup.yaml"
+
+// projectManifest declares a repository's development prerequisites:
+//
+//	name: my-service
+//	packages: [libpq-dev, protobuf-compiler]
+//	services: [postgresql]
+//	tools:
+//	  - name: golangci-lint
+//	    check: command -v golangci-lint
+//	    install:
+//	      - go install github.com/golangci/golangci-lint/cmd/golangci-lint@latest
+type projectManifest struct {
+	Name     string        `yaml:"name"`
+	Packages []string      `yaml:"packages"`
+	Services []string      `yaml:"services"`
+	Tools    []projectTool `yaml:"tools"`
+}
+
+// projectTool is one tool with its own presence check and install
+// commands, run through the same engine as catalog components.
+type projectTool struct {
+	Name    string   `yaml:"name"`
+	Check   string   `yaml:"check"`
+	Install []string `yaml:"install"`
+}
+
+// runProject installs the prerequisites of the repository the command
+// is run in, from its .setup.yaml.
+func runProject(args []string) error {
+	fs := flag.NewFlagSet("project", flag.ExitOnError)
+	file := fs.String("file", "", "project manifest to use instead of searching for "+projectManifestName)
+	fs.Parse(args)
+
+	path := *file
+	if path == "" {
+		var err error
+		if path, err = findProjectManifest(); err != nil {
+			logger.Error("%v", err)
+			return err
+		}
+	}
+	manifest, err := loadProjectManifest(path)
+	if err != nil {
+		logger.Error("%s: %v", path, err)
+		return err
+	}
+	cmds := projectComponents(manifest)
+	if len(cmds) == 0 {
+		logger.Info("%s declares no prerequisites", path)
+		return nil
+	}
+
+	release, err := acquireRunLock()
+	if err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+	defer release()
+	stop := trapSignals()
+	defer stop()
+	stopSudo := installer.KeepSudoAlive()
+	defer stopSudo()
+	logger.Info("installing prerequisites of %s (%s)", manifest.Name, path)
+	results, err := installer.RunAllResults(cmds)
+	printSummary(results)
+	if err != nil {
+		logger.Error("setup finished with errors")
+		return err
+	}
+	logger.Success("project prerequisites complete")
+	return nil
+}
+
+// findProjectManifest walks up from the working directory to the
+// filesystem root looking for the manifest.
+func findProjectManifest() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, projectManifestName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no %s found here or in any parent directory", projectManifestName)
+		}
+		dir = parent
+	}
+}
+
+// loadProjectManifest parses the manifest strictly, so a typoed key
+// fails instead of being silently ignored.
+func loadProjectManifest(path string) (projectManifest, error) {
+	var manifest projectManifest
+	f, err := os.Open(path)
+	if err != nil {
+		return manifest, err
+	}
+	defer f.Close()
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&manifest); err != nil {
+		return manifest, err
+	}
+	if manifest.Name == "" {
+		manifest.Name = filepath.Base(filepath.Dir(path))
+	}
+	return manifest, nil
+}
+
+// projectComponents turns the manifest into installer components:
+// packages as apt resources, each tool as its own component, services
+// enabled and started.
+func projectComponents(m projectManifest) []installer.InstallCommand {
+	var cmds []installer.InstallCommand
+	if len(m.Packages) > 0 {
+		var pkgs []installer.Resource
+		for _, pkg := range m.Packages {
+			pkgs = append(pkgs, resources.AptPackage{Package: pkg})
+		}
+		cmds = append(cmds, installer.InstallCommand{
+			Name:      m.Name + ": packages",
+			Resources: pkgs,
+		})
+	}
+	for _, tool := range m.Tools {
+		cmd := installer.InstallCommand{
+			Name:            m.Name + ": " + tool.Name,
+			InstallCommands: tool.Install,
+		}
+		if tool.Check != "" {
+			cmd.CheckCommands = []string{tool.Check}
+		}
+		cmds = append(cmds, cmd)
+	}
+	if len(m.Services) > 0 {
+		var checks, install []string
+		for _, svc := range m.Services {
+			checks = append(checks, fmt.Sprintf("systemctl is-active --quiet %s", svc))
+			install = append(install, fmt.Sprintf("sudo systemctl enable --now %s", svc))
+		}
+		cmds = append(cmds, installer.InstallCommand{
+			Name:            m.Name + ": services",
+			CheckCommands:   checks,
+			InstallCommands: install,
+		})
+	}
+	return cmds
+}
@@ -0,0 +1,116 @@
+// Package provision bootstraps a fresh VPS before the regular
+// components run: it creates the first non-root user with sudo rights
+// and carries root's authorized SSH keys over, so everything else can
+// run (and log in) as that user. Ported from the original bash-based
+// first-boot script.
+package provision
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/installer/fileedit"
+	"github.com/ishida722/setup/logger"
+)
+
+// CreateUser ensures the named user exists, is in the sudo group, and
+// has root's authorized SSH keys. Re-running is safe: an existing user
+// is left alone apart from the group and key sync, and no second
+// password is generated.
+func CreateUser(name string) error {
+	if os.Geteuid() != 0 {
+		return fmt.Errorf("provision-user must run as root")
+	}
+	if _, err := user.Lookup(name); err == nil {
+		logger.Skip("user %s already exists, skipping creation", name)
+	} else {
+		if err := installer.Run(fmt.Sprintf("useradd -m -s /bin/bash %q", name)); err != nil {
+			return err
+		}
+		password, err := randomPassword()
+		if err != nil {
+			return err
+		}
+		if err := setPassword(name, password); err != nil {
+			return err
+		}
+		// Shown once, straight on the terminal; it goes through no
+		// logger and lands in no log file.
+		fmt.Fprintf(os.Stderr, "generated password for %s: %s (change it after first login)\n", name, password)
+	}
+	if err := installer.Run(fmt.Sprintf("usermod -aG sudo %q", name)); err != nil {
+		return err
+	}
+	if err := copyAuthorizedKeys(name); err != nil {
+		return err
+	}
+	logger.Success("user %s provisioned", name)
+	return nil
+}
+
+// randomPassword generates a 24-character initial password. It only
+// needs to survive until the user's first login.
+func randomPassword() (string, error) {
+	var buf [18]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf[:]), nil
+}
+
+// setPassword feeds chpasswd over stdin, so the password appears in no
+// argv, trace, or audit line.
+func setPassword(name, password string) error {
+	cmd := exec.Command("chpasswd")
+	cmd.Stdin = strings.NewReader(name + ":" + password + "\n")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// copyAuthorizedKeys merges root's authorized keys into the user's,
+// preserving any keys already there, and fixes ownership and modes
+// the way sshd requires. A root account without keys is not an error.
+func copyAuthorizedKeys(name string) error {
+	data, err := os.ReadFile("/root/.ssh/authorized_keys")
+	if err != nil {
+		if os.IsNotExist(err) {
+			logger.Skip("no /root/.ssh/authorized_keys to copy")
+			return nil
+		}
+		return err
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return err
+	}
+	uid, _ := strconv.Atoi(u.Uid)
+	gid, _ := strconv.Atoi(u.Gid)
+	sshDir := filepath.Join(u.HomeDir, ".ssh")
+	if err := os.MkdirAll(sshDir, 0o700); err != nil {
+		return err
+	}
+	keys := filepath.Join(sshDir, "authorized_keys")
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		if _, err := fileedit.EnsureLine(keys, line); err != nil {
+			return err
+		}
+	}
+	for _, path := range []string{sshDir, keys} {
+		if err := os.Chown(path, uid, gid); err != nil {
+			return err
+		}
+	}
+	return os.Chmod(keys, 0o600)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/ishida722/setup/logger"
+	"github.com/ishida722/setup/provision"
+)
+
+// runProvisionUser creates the initial login user on a fresh VPS:
+// `setup provision-user -name ubuntu` as root gives a sudo-capable
+// user with root's SSH keys and a freshly generated password.
+func runProvisionUser(args []string) error {
+	fs := flag.NewFlagSet("provision-user", flag.ExitOnError)
+	name := fs.String("name", "ubuntu", "login name of the user to create")
+	fs.Parse(args)
+
+	if err := provision.CreateUser(*name); err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+	return nil
+}
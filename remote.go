@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ishida722/setup/logger"
+)
+
+// runRemote provisions one or more hosts over SSH: the running binary
+// is copied to each host and `setup install` is executed there. Hosts
+// run concurrently up to -concurrency, with output prefixed per host
+// and an aggregated summary at the end.
+func runRemote(args []string) error {
+	fs := flag.NewFlagSet("remote", flag.ExitOnError)
+	hosts := fs.String("hosts", "", "comma-separated SSH destinations (user@host)")
+	inventory := fs.String("inventory", "", "inventory file with hosts grouped by role")
+	groupName := fs.String("group", "", "inventory group to provision (default: every group)")
+	concurrency := fs.Int("concurrency", 4, "maximum hosts provisioned at once")
+	installArgs := fs.String("args", "", "flags passed to setup install on each host (appended to group args)")
+	fs.Parse(args)
+
+	targets, err := remoteTargets(*hosts, *inventory, *groupName, *installArgs)
+	if err != nil {
+		logger.Error("remote: %v", err)
+		return err
+	}
+	self, err := os.Executable()
+	if err != nil {
+		logger.Error("remote: cannot locate setup binary: %v", err)
+		return err
+	}
+
+	sem := make(chan struct{}, *concurrency)
+	results := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		i, t := i, t
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = provisionHost(t.host, self, t.args)
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for i, t := range targets {
+		if err := results[i]; err != nil {
+			logger.Error("%s: failed: %v", t.host, err)
+			failed++
+		} else {
+			logger.Success("%s: succeeded", t.host)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d/%d hosts failed", failed, len(targets))
+	}
+	return nil
+}
+
+// remoteTarget is one host to provision with its resolved install
+// flags.
+type remoteTarget struct {
+	host string
+	args string
+}
+
+// remoteTargets resolves -hosts / -inventory / -group into the host
+// list. Group args from the inventory come first, with extraArgs
+// appended so the command line can override per-group defaults.
+func remoteTargets(hosts, inventory, groupName, extraArgs string) ([]remoteTarget, error) {
+	var targets []remoteTarget
+	if hosts != "" {
+		for _, h := range strings.Split(hosts, ",") {
+			targets = append(targets, remoteTarget{host: strings.TrimSpace(h), args: extraArgs})
+		}
+	}
+	if inventory != "" {
+		groups, err := ParseInventory(inventory)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(groups))
+		if groupName != "" {
+			if _, ok := groups[groupName]; !ok {
+				return nil, fmt.Errorf("group %q not in %s", groupName, inventory)
+			}
+			names = append(names, groupName)
+		} else {
+			for name := range groups {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+		}
+		for _, name := range names {
+			g := groups[name]
+			args := strings.TrimSpace(g.Args + " " + extraArgs)
+			for _, h := range g.Hosts {
+				targets = append(targets, remoteTarget{host: h, args: args})
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no hosts given (use -hosts or -inventory)")
+	}
+	return targets, nil
+}
+
+// provisionHost copies the binary to the host and runs the install,
+// prefixing every output line with the host name.
+func provisionHost(host, binary, installArgs string) error {
+	if err := runPrefixed(host, "scp", binary, host+":/tmp/setup"); err != nil {
+		return fmt.Errorf("copy binary: %w", err)
+	}
+	remote := "/tmp/setup install -yes"
+	if installArgs != "" {
+		remote += " " + installArgs
+	}
+	return runPrefixed(host, "ssh", host, remote)
+}
+
+// runPrefixed runs the command and writes its combined output line by
+// line with a "[host]" prefix, keeping parallel runs readable.
+func runPrefixed(prefix string, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	cmd.Stderr = cmd.Stdout
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	prefixLines(prefix, out)
+	return cmd.Wait()
+}
+
+func prefixLines(prefix string, r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fmt.Printf("[%s] %s\n", prefix, scanner.Text())
+	}
+}
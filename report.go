@@ -0,0 +1,98 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+)
+
+// writeErrorReport bundles everything needed to debug a failed run
+// into a tar.gz — the transcript, per-component results, and
+// environment info — ready to attach to an issue against this repo.
+// It returns the archive path.
+func writeErrorReport(transcript []byte, results []installer.Result, args []string) (string, error) {
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "setup %s\narguments: %s\n\n", version, strings.Join(args, " "))
+	for _, r := range results {
+		fmt.Fprintf(&summary, "%-30s %-10s %s\n", r.Name, r.Action, r.Version)
+		if r.Err != nil {
+			fmt.Fprintf(&summary, "    error: %v\n", r.Err)
+		}
+	}
+
+	files := map[string][]byte{
+		"summary.txt":    []byte(summary.String()),
+		"transcript.log": transcript,
+		"system.txt":     systemInfo(),
+	}
+	if osRelease, err := os.ReadFile("/etc/os-release"); err == nil {
+		files["os-release"] = osRelease
+	}
+
+	path := fmt.Sprintf("%s/setup-report-%s.tar.gz", os.TempDir(), time.Now().Format("20060102-150405"))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	defer gz.Close()
+	tw := tar.NewWriter(gz)
+	defer tw.Close()
+	for name, content := range files {
+		hdr := &tar.Header{
+			Name:    "setup-report/" + name,
+			Mode:    0o644,
+			Size:    int64(len(content)),
+			ModTime: time.Now(),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return "", err
+		}
+		if _, err := tw.Write(content); err != nil {
+			return "", err
+		}
+	}
+	return path, nil
+}
+
+// systemInfo collects architecture, kernel, and free disk details.
+func systemInfo() []byte {
+	var b bytes.Buffer
+	for _, cmd := range []string{"uname -a", "uname -m", "df -h /", "free -h"} {
+		fmt.Fprintf(&b, "$ %s\n", cmd)
+		out, err := exec.Command("bash", "-c", cmd).CombinedOutput()
+		b.Write(out)
+		if err != nil {
+			fmt.Fprintf(&b, "(%v)\n", err)
+		}
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+// captureTranscript tees all log and command output into a bounded
+// buffer for the error report; call the returned restore func before
+// reading it. The buffer keeps the first and last 256 KB, so a verbose
+// apt upgrade cannot balloon memory on a small VPS.
+func captureTranscript() (*logger.BoundedBuffer, func()) {
+	buf := logger.NewBoundedBuffer(256 << 10)
+	prevOut, prevErr := installer.Output, installer.ErrOutput
+	prevLogger := logger.Default
+	installer.Output = io.MultiWriter(prevOut, buf)
+	installer.ErrOutput = io.MultiWriter(prevErr, buf)
+	logger.Default = logger.NewConsole(installer.Output, installer.ErrOutput)
+	return buf, func() {
+		installer.Output, installer.ErrOutput = prevOut, prevErr
+		logger.Default = prevLogger
+	}
+}
@@ -0,0 +1,162 @@
+// Package resources implements installer.Resource: declarative pieces
+// of machine state (a package at a version, a file with given
+// content, the login shell) that a run converges and status can
+// report drift on individually.
+package resources
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// AptPackage declares that an apt package is installed, optionally at
+// a specific version prefix (e.g. "1.2" matches "1.2.3-1ubuntu1").
+type AptPackage struct {
+	Package string
+	Version string
+}
+
+func (p AptPackage) ID() string { return "package " + p.Package }
+
+func (p AptPackage) Desired() string {
+	if p.Version == "" {
+		return "installed"
+	}
+	return "installed at " + p.Version
+}
+
+func (p AptPackage) Current() string {
+	v, ok := p.installedVersion()
+	if !ok {
+		return "not installed"
+	}
+	return "installed at " + v
+}
+
+func (p AptPackage) InSync() bool {
+	v, ok := p.installedVersion()
+	if !ok {
+		return false
+	}
+	return p.Version == "" || strings.HasPrefix(v, p.Version)
+}
+
+func (p AptPackage) Converge() error {
+	pkg := p.Package
+	if p.Version != "" {
+		pkg += "=" + p.Version + "*"
+	}
+	return installer.Run(fmt.Sprintf("sudo apt-get install -y %q", pkg))
+}
+
+// installedVersion asks dpkg directly instead of shelling a pipeline.
+func (p AptPackage) installedVersion() (string, bool) {
+	out, err := exec.Command("dpkg-query", "-W", "-f", "${db:Status-Status} ${Version}", p.Package).Output()
+	if err != nil {
+		return "", false
+	}
+	status, version, _ := strings.Cut(string(out), " ")
+	if status != "installed" {
+		return "", false
+	}
+	return version, true
+}
+
+// FileContent declares that a file exists with exactly the given
+// content; drift is detected by SHA-256 comparison so status never
+// prints file bodies.
+type FileContent struct {
+	Path    string
+	Content []byte
+	Mode    os.FileMode // 0 means 0644
+}
+
+func (f FileContent) ID() string      { return "file " + f.Path }
+func (f FileContent) Desired() string { return "content " + shortHash(f.Content) }
+
+func (f FileContent) Current() string {
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return "missing"
+	}
+	return "content " + shortHash(data)
+}
+
+func (f FileContent) InSync() bool {
+	data, err := os.ReadFile(f.Path)
+	return err == nil && sha256.Sum256(data) == sha256.Sum256(f.Content)
+}
+
+func (f FileContent) Converge() error {
+	mode := f.Mode
+	if mode == 0 {
+		mode = 0o644
+	}
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(f.Path, f.Content, mode)
+}
+
+func shortHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
+// LoginShell declares the current user's login shell, replacing the
+// getent-pipeline check the Fish component used.
+type LoginShell struct {
+	Shell string // absolute path, e.g. /usr/bin/fish
+}
+
+func (s LoginShell) ID() string      { return "login shell" }
+func (s LoginShell) Desired() string { return s.Shell }
+
+func (s LoginShell) Current() string {
+	shell, err := currentShell()
+	if err != nil {
+		return "unknown"
+	}
+	return shell
+}
+
+func (s LoginShell) InSync() bool {
+	shell, err := currentShell()
+	return err == nil && shell == s.Shell
+}
+
+func (s LoginShell) Converge() error {
+	u, err := user.Current()
+	if err != nil {
+		return err
+	}
+	return installer.Run(fmt.Sprintf("sudo chsh -s %q %q", s.Shell, u.Username))
+}
+
+// currentShell parses /etc/passwd for the user's login shell, which
+// os/user does not expose.
+func currentShell() (string, error) {
+	u, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile("/etc/passwd")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) == 7 && fields[0] == u.Username {
+			return fields[6], nil
+		}
+	}
+	return "", fmt.Errorf("%s not found in /etc/passwd", u.Username)
+}
@@ -0,0 +1,34 @@
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileContentDriftAndConverge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "motd")
+	r := FileContent{Path: path, Content: []byte("hello\n")}
+
+	if r.InSync() {
+		t.Fatal("missing file reported in sync")
+	}
+	if got := r.Current(); got != "missing" {
+		t.Fatalf("Current() = %q, want missing", got)
+	}
+	if err := r.Converge(); err != nil {
+		t.Fatal(err)
+	}
+	if !r.InSync() {
+		t.Fatal("converged file reported out of sync")
+	}
+	if err := os.WriteFile(path, []byte("tampered\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if r.InSync() {
+		t.Fatal("modified file reported in sync")
+	}
+	if r.Current() == r.Desired() {
+		t.Fatal("drifted Current() matches Desired()")
+	}
+}
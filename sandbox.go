@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ishida722/setup/logger"
+	"github.com/ishida722/setup/state"
+)
+
+// runSandbox executes the selected profile inside a throwaway Ubuntu
+// container instead of the local machine, so manifest changes can be
+// validated before touching real hosts. The running binary is mounted
+// into the container and run as root (with sudo installed first,
+// since every command goes through it).
+func runSandbox(image string, installArgs []string) error {
+	engine, err := containerEngine()
+	if err != nil {
+		logger.Error("sandbox: %v", err)
+		return err
+	}
+	self, err := os.Executable()
+	if err != nil {
+		logger.Error("sandbox: cannot locate setup binary: %v", err)
+		return err
+	}
+
+	inner := "apt-get update && apt-get install -y sudo curl ca-certificates && setup install -yes"
+	if len(installArgs) > 0 {
+		inner += " " + strings.Join(installArgs, " ")
+	}
+	logger.Info("running sandbox in %s via %s", image, engine)
+	run := []string{"run", "--rm", "-v", self + ":/usr/local/bin/setup:ro"}
+	// Share the host artifact cache so repeated sandbox runs reuse
+	// downloads instead of hitting the network every time.
+	if cache, err := state.CacheDir(); err == nil {
+		run = append(run, "-v", cache+":/root/.cache/setup")
+	}
+	run = append(run, image, "bash", "-c", inner)
+	cmd := exec.Command(engine, run...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		logger.Error("sandbox run failed: %v", err)
+		return err
+	}
+	logger.Success("sandbox run succeeded in %s", image)
+	return nil
+}
+
+// containerEngine prefers docker, falls back to podman.
+func containerEngine() (string, error) {
+	for _, engine := range []string{"docker", "podman"} {
+		if _, err := exec.LookPath(engine); err == nil {
+			return engine, nil
+		}
+	}
+	return "", fmt.Errorf("neither docker nor podman found")
+}
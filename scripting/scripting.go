@@ -0,0 +1,117 @@
+// Package scripting executes user-provided install logic written in
+// Starlark, so manifest components can express InstallFunc-equivalent
+// steps without writing Go and recompiling. Scripts get a small
+// stdlib — run, download, template, lineinfile — covering the
+// patterns the built-in components use.
+package scripting
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/installer/fileedit"
+	"github.com/ishida722/setup/logger"
+)
+
+// InstallFunc wraps a script as an InstallCommand.InstallFunc.
+func InstallFunc(name, script string) func() error {
+	return func() error { return Run(name, script) }
+}
+
+// Run executes the script with the setup builtins. name labels the
+// script in errors and print output.
+func Run(name, script string) error {
+	thread := &starlark.Thread{
+		Name:  name,
+		Print: func(_ *starlark.Thread, msg string) { logger.Info("%s: %s", name, msg) },
+	}
+	_, err := starlark.ExecFile(thread, name, script, builtins())
+	if err != nil {
+		if evalErr, ok := err.(*starlark.EvalError); ok {
+			return fmt.Errorf("%s: %s", name, evalErr.Backtrace())
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// builtins is the stdlib every script sees.
+func builtins() starlark.StringDict {
+	return starlark.StringDict{
+		"run":        starlark.NewBuiltin("run", builtinRun),
+		"download":   starlark.NewBuiltin("download", builtinDownload),
+		"template":   starlark.NewBuiltin("template", builtinTemplate),
+		"lineinfile": starlark.NewBuiltin("lineinfile", builtinLineInFile),
+	}
+}
+
+// run(command) executes a shell command through the installer's
+// runner, so -record/-replay and the audit log apply to scripted
+// steps too. Fails the script on a non-zero exit.
+func builtinRun(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var command string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "command", &command); err != nil {
+		return nil, err
+	}
+	if err := installer.Run(command); err != nil {
+		return nil, fmt.Errorf("run(%q): %w", command, err)
+	}
+	return starlark.None, nil
+}
+
+// download(url, dest) fetches through the shared HTTP cache.
+func builtinDownload(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var url, dest string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "url", &url, "dest", &dest); err != nil {
+		return nil, err
+	}
+	if err := installer.Fetch(url, dest); err != nil {
+		return nil, fmt.Errorf("download(%q): %w", url, err)
+	}
+	return starlark.None, nil
+}
+
+// template(dest, content, vars={}) renders content as a Go
+// text/template with vars and writes it to dest through
+// fileedit.Template, so the write only happens on change, the previous
+// version is backed up, and the file is recorded as managed.
+func builtinTemplate(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var dest, content string
+	var vars *starlark.Dict
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "dest", &dest, "content", &content, "vars?", &vars); err != nil {
+		return nil, err
+	}
+	data := map[string]string{}
+	if vars != nil {
+		for _, item := range vars.Items() {
+			key, ok := starlark.AsString(item[0])
+			if !ok {
+				return nil, fmt.Errorf("template: vars key %v is not a string", item[0])
+			}
+			value, ok := starlark.AsString(item[1])
+			if !ok {
+				return nil, fmt.Errorf("template: vars[%q] is not a string", key)
+			}
+			data[key] = value
+		}
+	}
+	if _, err := fileedit.Template(dest, content, data); err != nil {
+		return nil, fmt.Errorf("template(%q): %w", dest, err)
+	}
+	return starlark.None, nil
+}
+
+// lineinfile(path, line) ensures the file contains the exact line,
+// appending it when missing; existing content is never reordered.
+func builtinLineInFile(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path, line string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "path", &path, "line", &line); err != nil {
+		return nil, err
+	}
+	if _, err := fileedit.EnsureLine(path, line); err != nil {
+		return nil, err
+	}
+	return starlark.None, nil
+}
@@ -0,0 +1,57 @@
+package scripting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/installer/installertest"
+)
+
+func TestRunBuiltinUsesInstallerRunner(t *testing.T) {
+	fake := &installertest.FakeRunner{}
+	prev := installer.Runner
+	installer.Runner = fake
+	defer func() { installer.Runner = prev }()
+
+	err := Run("test", `run("echo hello")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.Calls) != 1 || fake.Calls[0] != "echo hello" {
+		t.Fatalf("calls = %v, want [echo hello]", fake.Calls)
+	}
+}
+
+func TestTemplateAndLineInFile(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "app.conf")
+	rc := filepath.Join(dir, "rc")
+
+	script := `
+template(` + quote(conf) + `, "user = {{.user}}\n", vars={"user": "ishida"})
+lineinfile(` + quote(rc) + `, "export EDITOR=nvim")
+lineinfile(` + quote(rc) + `, "export EDITOR=nvim")
+`
+	if err := Run("test", script); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(conf); string(got) != "user = ishida\n" {
+		t.Fatalf("template wrote %q", got)
+	}
+	if got, _ := os.ReadFile(rc); string(got) != "export EDITOR=nvim\n" {
+		t.Fatalf("lineinfile is not idempotent: %q", got)
+	}
+}
+
+func TestScriptErrorsAreReported(t *testing.T) {
+	if err := Run("test", `nonsense(`); err == nil {
+		t.Fatal("syntax error not reported")
+	}
+	if err := Run("test", `fail("boom")`); err == nil {
+		t.Fatal("fail() not reported")
+	}
+}
+
+func quote(s string) string { return `"` + s + `"` }
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ishida722/setup/components"
+	"github.com/ishida722/setup/events"
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+)
+
+//go:embed webui.html
+var webUI []byte
+
+// runServe starts a local HTTP API (status, manifest, run, event
+// stream) so other tooling can drive setup programmatically. With
+// -web it also serves a small dashboard for browsing the catalog,
+// starting runs, and following the live log — handy when walking
+// someone through provisioning without a terminal. There is no
+// authentication, so the default address stays on loopback.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8833", "listen address (loopback only; the server has no auth)")
+	web := fs.Bool("web", false, "serve the browser dashboard at /")
+	fs.Parse(args)
+
+	s := &server{}
+	// Route lifecycle events to the SSE subscribers for the duration
+	// of the server; serve owns the process, so no chaining with the
+	// -events flag is attempted.
+	installer.Events = events.NewEmitter(&s.events)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/status", s.handleStatus)
+	mux.HandleFunc("/api/catalog", s.handleCatalog)
+	mux.HandleFunc("/api/manifest", s.handleManifest)
+	mux.HandleFunc("/api/run", s.handleRun)
+	mux.HandleFunc("/api/log", s.handleLog)
+	mux.HandleFunc("/api/events", s.handleEvents)
+	mux.HandleFunc("/api/report", s.handleReport)
+	if *web {
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write(webUI)
+		})
+	}
+	logger.Info("listening on http://%s", *addr)
+	return http.ListenAndServe(*addr, mux)
+}
+
+// server holds the one-run-at-a-time state behind the HTTP handlers.
+type server struct {
+	mu       sync.Mutex
+	running  bool
+	manifest []string // component names from POST /api/manifest
+	log      webLog
+	events   eventFanout
+	report   string // latest error report archive, if any
+}
+
+// handleStatus reports the machine's component state, in the same
+// shape `setup state export` writes, so portal clients and snapshot
+// files stay interchangeable.
+func (s *server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	host, _ := os.Hostname()
+	snap := Snapshot{Host: host, Time: time.Now()}
+	for _, c := range components.Catalog() {
+		if !c.HasChecks() {
+			continue
+		}
+		sc := SnapshotComponent{Name: c.Name, Installed: c.Installed()}
+		if sc.Installed {
+			sc.Version = c.Version()
+		}
+		snap.Components = append(snap.Components, sc)
+	}
+	writeJSON(w, snap)
+}
+
+// handleManifest stores (POST) or returns (GET) the component
+// selection subsequent runs default to, so a controller can submit a
+// manifest once and trigger runs separately.
+func (s *server) handleManifest(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.Lock()
+		manifest := s.manifest
+		s.mu.Unlock()
+		writeJSON(w, map[string]any{"components": manifest})
+	case http.MethodPost:
+		var req struct {
+			Components []string `json:"components"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, err := catalogByName(req.Components); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.manifest = req.Components
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "GET or POST only", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleEvents streams lifecycle events as server-sent events until
+// the client disconnects.
+func (s *server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	ch := s.events.subscribe()
+	defer s.events.unsubscribe(ch)
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case line := <-ch:
+			fmt.Fprintf(w, "data: %s\n\n", bytes.TrimRight(line, "\n"))
+			flusher.Flush()
+		}
+	}
+}
+
+// handleCatalog lists the built-in components with their install
+// state, for the dashboard's component picker.
+func (s *server) handleCatalog(w http.ResponseWriter, r *http.Request) {
+	type item struct {
+		Name      string `json:"name"`
+		Installed bool   `json:"installed"`
+	}
+	var items []item
+	for _, e := range components.CatalogEntries() {
+		c := e.Build()
+		items = append(items, item{Name: c.Name, Installed: c.HasChecks() && c.Installed()})
+	}
+	writeJSON(w, items)
+}
+
+// handleRun starts a run over the named components (all when the list
+// is empty). Only one run at a time; a second request gets 409.
+func (s *server) handleRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST only", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Components []string `json:"components"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Components) == 0 {
+		s.mu.Lock()
+		req.Components = s.manifest
+		s.mu.Unlock()
+	}
+	cmds, err := catalogByName(req.Components)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		http.Error(w, "a run is already in progress", http.StatusConflict)
+		return
+	}
+	s.running = true
+	s.log.reset()
+	s.mu.Unlock()
+	go s.run(cmds)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// run executes the components with all output teed into the web log,
+// then clears the running flag. A failed run leaves an error report
+// for /api/report.
+func (s *server) run(cmds []installer.InstallCommand) {
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+	prevOut, prevErr := installer.Output, installer.ErrOutput
+	prevLogger := logger.Default
+	installer.Output = io.MultiWriter(prevOut, &s.log)
+	installer.ErrOutput = io.MultiWriter(prevErr, &s.log)
+	logger.Default = logger.NewConsole(installer.Output, installer.ErrOutput)
+	defer func() {
+		installer.Output, installer.ErrOutput = prevOut, prevErr
+		logger.Default = prevLogger
+	}()
+
+	installer.Events.Emit(events.Event{Event: "run_started"})
+	results, err := installer.RunAllResults(cmds)
+	printSummary(results)
+	recordRun(nil, results, err)
+	if err != nil {
+		installer.Events.Emit(events.Event{Event: "run_finished", Error: err.Error()})
+	} else {
+		installer.Events.Emit(events.Event{Event: "run_finished"})
+	}
+	if err != nil {
+		logger.Error("setup finished with errors")
+		if path, rerr := writeErrorReport(s.log.bytes(), results, nil); rerr == nil {
+			s.mu.Lock()
+			s.report = path
+			s.mu.Unlock()
+		}
+		return
+	}
+	logger.Success("setup complete")
+}
+
+// handleLog returns log output past the client's offset, so the
+// dashboard can poll for the live run log.
+func (s *server) handleLog(w http.ResponseWriter, r *http.Request) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	data, next := s.log.since(offset)
+	s.mu.Lock()
+	running := s.running
+	s.mu.Unlock()
+	writeJSON(w, map[string]any{
+		"data":    string(data),
+		"offset":  next,
+		"running": running,
+	})
+}
+
+// handleReport serves the latest error report archive.
+func (s *server) handleReport(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	path := s.report
+	s.mu.Unlock()
+	if path == "" {
+		http.Error(w, "no report available", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Disposition", "attachment; filename=setup-report.tar.gz")
+	http.ServeFile(w, r, path)
+}
+
+// catalogByName resolves component names against the catalog; an
+// empty list selects everything, in catalog order.
+func catalogByName(names []string) ([]installer.InstallCommand, error) {
+	entries := components.CatalogEntries()
+	if len(names) == 0 {
+		return components.Catalog(), nil
+	}
+	byName := map[string]components.CatalogEntry{}
+	for _, e := range entries {
+		byName[e.Name] = e
+	}
+	var cmds []installer.InstallCommand
+	for _, e := range entries {
+		for _, name := range names {
+			if e.Name == name {
+				cmds = append(cmds, e.Build())
+				break
+			}
+		}
+	}
+	for _, name := range names {
+		if _, ok := byName[name]; !ok {
+			return nil, fmt.Errorf("unknown component %q", name)
+		}
+	}
+	return cmds, nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// webLogLimit caps the in-memory run log; a run that outgrows it
+// keeps only the tail (the dashboard streams incrementally, so
+// clients that kept up lose nothing).
+const webLogLimit = 1 << 20
+
+// webLog is an append-only buffer the dashboard polls with an offset.
+// Offsets count total bytes ever written, so trimming the front does
+// not disturb clients.
+type webLog struct {
+	mu      sync.Mutex
+	data    []byte
+	trimmed int // bytes dropped from the front
+}
+
+func (l *webLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.data = append(l.data, p...)
+	if len(l.data) > webLogLimit {
+		drop := len(l.data) - webLogLimit
+		l.data = append([]byte(nil), l.data[drop:]...)
+		l.trimmed += drop
+	}
+	return len(p), nil
+}
+
+// since returns everything written after offset and the new offset.
+func (l *webLog) since(offset int) ([]byte, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	start := offset - l.trimmed
+	if start < 0 {
+		start = 0
+	}
+	if start > len(l.data) {
+		start = len(l.data)
+	}
+	out := append([]byte(nil), l.data[start:]...)
+	return out, l.trimmed + len(l.data)
+}
+
+func (l *webLog) bytes() []byte {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]byte(nil), l.data...)
+}
+
+func (l *webLog) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.data, l.trimmed = nil, 0
+}
+
+// eventFanout is an io.Writer broadcasting each NDJSON event line to
+// every SSE subscriber. Slow subscribers drop events rather than
+// block the run.
+type eventFanout struct {
+	mu   sync.Mutex
+	subs map[chan []byte]struct{}
+}
+
+func (f *eventFanout) Write(p []byte) (int, error) {
+	line := append([]byte(nil), p...)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for ch := range f.subs {
+		select {
+		case ch <- line:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+func (f *eventFanout) subscribe() chan []byte {
+	ch := make(chan []byte, 64)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.subs == nil {
+		f.subs = map[chan []byte]struct{}{}
+	}
+	f.subs[ch] = struct{}{}
+	return ch
+}
+
+func (f *eventFanout) unsubscribe(ch chan []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.subs, ch)
+}
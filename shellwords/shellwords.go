@@ -0,0 +1,155 @@
+// Package shellwords splits command strings into words with POSIX-like
+// quoting rules. Naive whitespace splitting silently mis-handles
+// quoted arguments ("--title=\"my repo\"" becomes two words), so every
+// place that needs a command's words — sudo detection, shell-free
+// check execution — goes through this tokenizer instead.
+//
+// It tokenizes words only: quotes, backslash escapes, and optional
+// $VAR/${VAR} expansion. Shell operators (|, &&, ;) are not
+// interpreted and come back as ordinary words; commands relying on
+// them must still run through a real shell.
+package shellwords
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Split tokenizes s without environment expansion: $VAR stays literal.
+// It returns an error for an unterminated quote or a trailing
+// backslash, which a shell would also reject.
+func Split(s string) ([]string, error) {
+	return split(s, nil)
+}
+
+// SplitEnv is Split with $VAR and ${VAR} expanded through getenv in
+// unquoted and double-quoted context. Single quotes suppress expansion,
+// as in a shell.
+func SplitEnv(s string, getenv func(string) string) ([]string, error) {
+	if getenv == nil {
+		getenv = func(string) string { return "" }
+	}
+	return split(s, getenv)
+}
+
+func split(s string, getenv func(string) string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		started bool // distinguishes "" (quoted empty word) from no word
+	)
+	emit := func() {
+		if started {
+			words = append(words, word.String())
+			word.Reset()
+			started = false
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case ' ', '\t', '\n':
+			emit()
+		case '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			word.WriteString(s[i+1 : i+1+end])
+			started = true
+			i += end + 1
+		case '"':
+			i++
+			started = true
+			for {
+				if i >= len(s) {
+					return nil, fmt.Errorf("unterminated double quote")
+				}
+				switch s[i] {
+				case '"':
+				case '\\':
+					if i+1 >= len(s) {
+						return nil, fmt.Errorf("trailing backslash")
+					}
+					// Inside double quotes backslash only escapes
+					// these; otherwise it is literal.
+					if next := s[i+1]; next == '"' || next == '\\' || next == '$' || next == '`' {
+						word.WriteByte(next)
+						i += 2
+						continue
+					}
+					word.WriteByte('\\')
+					i++
+					continue
+				case '$':
+					if getenv != nil {
+						name, width := varName(s[i+1:])
+						if width > 0 {
+							word.WriteString(getenv(name))
+							i += width + 1
+							continue
+						}
+					}
+					word.WriteByte('$')
+					i++
+					continue
+				default:
+					word.WriteByte(s[i])
+					i++
+					continue
+				}
+				break
+			}
+		case '\\':
+			if i+1 >= len(s) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			word.WriteByte(s[i+1])
+			started = true
+			i++
+		case '$':
+			if getenv != nil {
+				name, width := varName(s[i+1:])
+				if width > 0 {
+					// An unquoted expansion to nothing produces no
+					// word, matching shell behavior.
+					if v := getenv(name); v != "" {
+						word.WriteString(v)
+						started = true
+					}
+					i += width
+					continue
+				}
+			}
+			word.WriteByte('$')
+			started = true
+		default:
+			word.WriteByte(c)
+			started = true
+		}
+	}
+	emit()
+	return words, nil
+}
+
+// varName parses a variable reference right after a '$': NAME or
+// {NAME}. It returns the name and how many bytes it spans, or 0 when
+// what follows is not a variable reference.
+func varName(s string) (string, int) {
+	if strings.HasPrefix(s, "{") {
+		end := strings.IndexByte(s, '}')
+		if end < 1 {
+			return "", 0
+		}
+		return s[1:end], end + 1
+	}
+	end := 0
+	for end < len(s) && (isAlnum(s[end]) || s[end] == '_') {
+		end++
+	}
+	return s[:end], end
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,115 @@
+package shellwords
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"nvim", []string{"nvim"}},
+		{"command -v node", []string{"command", "-v", "node"}},
+		{"a  b\tc\nd", []string{"a", "b", "c", "d"}},
+		{`echo 'hello world'`, []string{"echo", "hello world"}},
+		{`echo "hello world"`, []string{"echo", "hello world"}},
+		{`echo ''`, []string{"echo", ""}},
+		{`echo ""`, []string{"echo", ""}},
+		{`echo a'b c'd`, []string{"echo", "ab cd"}},
+		{`echo 'it'\''s'`, []string{"echo", "it's"}},
+		{`echo "she said \"hi\""`, []string{"echo", `she said "hi"`}},
+		{`echo back\\slash`, []string{"echo", `back\slash`}},
+		{`echo escaped\ space`, []string{"echo", "escaped space"}},
+		{`echo "a\tb"`, []string{"echo", `a\tb`}}, // \t literal inside double quotes
+		{`echo '$HOME'`, []string{"echo", "$HOME"}},
+		{`echo "$HOME"`, []string{"echo", "$HOME"}}, // Split never expands
+		{`echo "\$HOME"`, []string{"echo", "$HOME"}},
+		{`a | b && c`, []string{"a", "|", "b", "&&", "c"}}, // operators are plain words
+		{`git commit -m "initial commit"`, []string{"git", "commit", "-m", "initial commit"}},
+	}
+	for _, tt := range tests {
+		got, err := Split(tt.in)
+		if err != nil {
+			t.Errorf("Split(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Split(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSplitErrors(t *testing.T) {
+	for _, in := range []string{
+		`echo 'unterminated`,
+		`echo "unterminated`,
+		`echo trailing\`,
+		`echo "trailing\`,
+	} {
+		if got, err := Split(in); err == nil {
+			t.Errorf("Split(%q) = %q, want error", in, got)
+		}
+	}
+}
+
+func TestSplitEnv(t *testing.T) {
+	getenv := func(name string) string {
+		return map[string]string{
+			"HOME": "/home/u",
+			"ARCH": "x86_64",
+		}[name]
+	}
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{`ls $HOME`, []string{"ls", "/home/u"}},
+		{`ls ${HOME}/src`, []string{"ls", "/home/u/src"}},
+		{`ls "$HOME/my dir"`, []string{"ls", "/home/u/my dir"}},
+		{`echo '$HOME'`, []string{"echo", "$HOME"}},
+		{`echo yazi-$ARCH.zip`, []string{"echo", "yazi-x86_64.zip"}},
+		{`echo $UNSET`, []string{"echo"}}, // empty expansion leaves no word
+		{`echo "$UNSET"`, []string{"echo", ""}},
+		{`echo $`, []string{"echo", "$"}},
+		{`echo 100$`, []string{"echo", "100$"}},
+	}
+	for _, tt := range tests {
+		got, err := SplitEnv(tt.in, getenv)
+		if err != nil {
+			t.Errorf("SplitEnv(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitEnv(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func FuzzSplit(f *testing.F) {
+	for _, seed := range []string{
+		"", "a b c", `a "b c"`, `'x y'`, `\ `, `"$HOME"`, `a\`, `"unclosed`,
+		`echo 'it'\''s'`, "tab\tsep", `${X}y`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, in string) {
+		words, err := Split(in)
+		if err != nil {
+			return
+		}
+		// Deterministic: the same input always splits the same way.
+		again, err2 := Split(in)
+		if err2 != nil || !reflect.DeepEqual(words, again) {
+			t.Fatalf("Split(%q) not deterministic: %q / %q (%v)", in, words, again, err2)
+		}
+		// Expansion must never change the word count rules: it can only
+		// substitute text, never invent a parse error.
+		if _, err := SplitEnv(in, func(string) string { return "v" }); err != nil {
+			t.Fatalf("SplitEnv(%q) failed where Split succeeded: %v", in, err)
+		}
+	})
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ishida722/setup/components"
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+	"github.com/ishida722/setup/resources"
+	"github.com/ishida722/setup/shellwords"
+	"github.com/ishida722/setup/state"
+)
+
+// keepSnapshots bounds the snapshot directory; old snapshots are
+// pruned oldest-first.
+const keepSnapshots = 10
+
+// envSnapshot records what a run was about to touch, so `setup
+// restore` can put the machine back if the new setup misbehaves.
+type envSnapshot struct {
+	Time     time.Time     `json:"time"`
+	Shell    string        `json:"shell,omitempty"`
+	Paths    []snapPath    `json:"paths"`
+	Packages []snapPackage `json:"packages"`
+}
+
+// snapPath is one file or directory covered by the snapshot; absent
+// paths are recorded so restore can remove what the run created.
+type snapPath struct {
+	Path    string `json:"path"`
+	Existed bool   `json:"existed"`
+}
+
+// snapPackage records whether an apt package the plan installs was
+// already present.
+type snapPackage struct {
+	Name      string `json:"name"`
+	Installed bool   `json:"installed"`
+}
+
+// captureEnvSnapshot snapshots the files, packages, and shell setting
+// the selected components touch, under the state directory. It
+// returns the snapshot name.
+func captureEnvSnapshot(cmds []installer.InstallCommand) (string, error) {
+	dir, err := snapshotDir()
+	if err != nil {
+		return "", err
+	}
+	name := time.Now().Format("20060102-150405")
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return "", err
+	}
+
+	snap := envSnapshot{Time: time.Now(), Shell: resources.LoginShell{}.Current()}
+	paths := touchedPaths(cmds)
+	for _, p := range aptPackagesFrom(cmds) {
+		snap.Packages = append(snap.Packages, snapPackage{
+			Name:      p,
+			Installed: resources.AptPackage{Package: p}.InSync(),
+		})
+	}
+
+	tarPath := filepath.Join(path, "files.tar.gz")
+	f, err := os.Create(tarPath)
+	if err != nil {
+		return "", err
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, p := range paths {
+		existed, err := tarTree(tw, p)
+		if err != nil {
+			tw.Close()
+			gz.Close()
+			f.Close()
+			return "", err
+		}
+		snap.Paths = append(snap.Paths, snapPath{Path: p, Existed: existed})
+	}
+	for _, c := range []io.Closer{tw, gz, f} {
+		if err := c.Close(); err != nil {
+			return "", err
+		}
+	}
+
+	meta, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(path, "meta.json"), meta, 0o644); err != nil {
+		return "", err
+	}
+	pruneSnapshots(dir)
+	return name, nil
+}
+
+// runRestore reverts the machine to a pre-run snapshot:
+//
+//	setup restore           # list snapshots
+//	setup restore <name>    # restore one (or "latest")
+func runRestore(args []string) error {
+	dir, err := snapshotDir()
+	if err != nil {
+		return err
+	}
+	names := snapshotNames(dir)
+	if len(args) == 0 {
+		if len(names) == 0 {
+			logger.Info("no snapshots recorded yet (one is taken before every install run)")
+			return nil
+		}
+		for _, n := range names {
+			fmt.Println(n)
+		}
+		return nil
+	}
+	name := args[0]
+	if name == "latest" {
+		if len(names) == 0 {
+			logger.Error("no snapshots recorded yet")
+			return fmt.Errorf("no snapshots")
+		}
+		name = names[len(names)-1]
+	}
+	path := filepath.Join(dir, name)
+	data, err := os.ReadFile(filepath.Join(path, "meta.json"))
+	if err != nil {
+		logger.Error("unknown snapshot %q", name)
+		return err
+	}
+	var snap envSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return err
+	}
+	logger.Info("restoring snapshot %s (%s)", name, snap.Time.Format("2006-01-02 15:04"))
+
+	// Paths the run created are removed, captured ones re-extracted.
+	for _, p := range snap.Paths {
+		if !p.Existed {
+			if _, err := os.Stat(p.Path); err == nil {
+				logger.Info("removing %s (did not exist at snapshot time)", p.Path)
+				if err := os.RemoveAll(p.Path); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := os.RemoveAll(p.Path); err != nil {
+			return err
+		}
+	}
+	if err := untarTree(filepath.Join(path, "files.tar.gz")); err != nil {
+		return err
+	}
+
+	var remove []string
+	for _, p := range snap.Packages {
+		if !p.Installed && (resources.AptPackage{Package: p.Name}).InSync() {
+			remove = append(remove, p.Name)
+		}
+	}
+	if len(remove) > 0 {
+		logger.Info("removing packages installed since the snapshot: %s", strings.Join(remove, ", "))
+		if err := installer.Run("sudo apt-get remove -y " + strings.Join(remove, " ")); err != nil {
+			return err
+		}
+	}
+
+	if snap.Shell != "" && !(resources.LoginShell{Shell: snap.Shell}).InSync() {
+		logger.Info("restoring login shell to %s", snap.Shell)
+		if err := (resources.LoginShell{Shell: snap.Shell}).Converge(); err != nil {
+			return err
+		}
+	}
+	logger.Success("restored snapshot %s", name)
+	return nil
+}
+
+func snapshotDir() (string, error) {
+	base, err := state.Dir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, "snapshots")
+	return dir, os.MkdirAll(dir, 0o755)
+}
+
+// snapshotNames lists snapshots oldest first; the timestamp format
+// sorts chronologically.
+func snapshotNames(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func pruneSnapshots(dir string) {
+	names := snapshotNames(dir)
+	for len(names) > keepSnapshots {
+		os.RemoveAll(filepath.Join(dir, names[0]))
+		names = names[1:]
+	}
+}
+
+// touchedPaths lists the files and directories the selected
+// components write to: the config repo clones plus any declared
+// FileContent resources.
+func touchedPaths(cmds []installer.InstallCommand) []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	var paths []string
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+	for _, c := range cmds {
+		if c.Name == "configuration repositories" {
+			for _, repo := range components.DefaultDotfiles {
+				add(filepath.Join(home, repo.Dest))
+			}
+		}
+		for _, r := range c.Resources {
+			if fc, ok := r.(resources.FileContent); ok {
+				add(fc.Path)
+			}
+		}
+	}
+	return paths
+}
+
+// aptPackagesFrom extracts the package names the components' apt-get
+// install commands would install.
+func aptPackagesFrom(cmds []installer.InstallCommand) []string {
+	seen := map[string]bool{}
+	var pkgs []string
+	for _, c := range cmds {
+		for _, command := range c.InstallCommands {
+			words, err := shellwords.Split(command)
+			if err != nil {
+				continue
+			}
+			if len(words) > 0 && words[0] == "sudo" {
+				words = words[1:]
+			}
+			if len(words) < 3 || words[0] != "apt-get" || words[1] != "install" {
+				continue
+			}
+			for _, w := range words[2:] {
+				if strings.HasPrefix(w, "-") || seen[w] {
+					continue
+				}
+				seen[w] = true
+				pkgs = append(pkgs, w)
+			}
+		}
+	}
+	return pkgs
+}
+
+// tarTree adds path (a file or directory tree) to the archive with
+// slash-trimmed absolute names, reporting whether it existed.
+func tarTree(tw *tar.Writer, root string) (bool, error) {
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return false, nil
+	}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = strings.TrimPrefix(path, "/")
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	return true, err
+}
+
+// untarTree extracts an archive written by tarTree back to /.
+func untarTree(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		dest := "/" + hdr.Name
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(dest, hdr.FileInfo().Mode()); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, hdr.FileInfo().Mode())
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+				return err
+			}
+			os.Remove(dest)
+			if err := os.Symlink(hdr.Linkname, dest); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package state
+
+// LoadChannels returns the release channel each component on this
+// machine follows, as recorded by `setup update --channel`.
+func LoadChannels() map[string]string {
+	channels := load().Channels
+	if channels == nil {
+		channels = map[string]string{}
+	}
+	return channels
+}
+
+// RecordChannel remembers the channel one component follows, so later
+// plain runs keep updating along it.
+func RecordChannel(name, channel string) {
+	f := load()
+	if f.Channels == nil {
+		f.Channels = map[string]string{}
+	}
+	f.Channels[name] = channel
+	save(f)
+}
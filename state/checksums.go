@@ -0,0 +1,17 @@
+package state
+
+// LoadChecksums returns the recorded SHA-256 (hex) per binary path.
+func LoadChecksums() map[string]string {
+	sums := load().Checksums
+	if sums == nil {
+		sums = map[string]string{}
+	}
+	return sums
+}
+
+// SaveChecksums records binary checksums for integrity verification.
+func SaveChecksums(sums map[string]string) {
+	f := load()
+	f.Checksums = sums
+	save(f)
+}
@@ -0,0 +1,39 @@
+package state
+
+import "time"
+
+// keepRuns caps the history so state.json stays small on machines with
+// a scheduled update timer.
+const keepRuns = 50
+
+// Run is one recorded setup run.
+type Run struct {
+	Time    time.Time `json:"time"`
+	Args    []string  `json:"args,omitempty"` // the profile: install flags as given
+	Outcome string    `json:"outcome"`        // "ok" or the first error
+	Steps   []RunStep `json:"steps,omitempty"`
+}
+
+// RunStep is one component's outcome within a run.
+type RunStep struct {
+	Name       string `json:"name"`
+	Action     string `json:"action"` // installed, skipped, or failed
+	Version    string `json:"version,omitempty"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+}
+
+// LoadRuns returns the recorded runs, oldest first.
+func LoadRuns() []Run {
+	return load().Runs
+}
+
+// AppendRun records a finished run, dropping the oldest entries beyond
+// the cap.
+func AppendRun(r Run) {
+	f := load()
+	f.Runs = append(f.Runs, r)
+	if len(f.Runs) > keepRuns {
+		f.Runs = f.Runs[len(f.Runs)-keepRuns:]
+	}
+	save(f)
+}
@@ -0,0 +1,21 @@
+package state
+
+// LoadManagedFiles returns the recorded SHA-256 (hex) per templated
+// file path, for drift detection against what setup last wrote.
+func LoadManagedFiles() map[string]string {
+	files := load().ManagedFiles
+	if files == nil {
+		files = map[string]string{}
+	}
+	return files
+}
+
+// RecordManagedFile records the content hash of one managed file.
+func RecordManagedFile(path, sum string) {
+	f := load()
+	if f.ManagedFiles == nil {
+		f.ManagedFiles = map[string]string{}
+	}
+	f.ManagedFiles[path] = sum
+	save(f)
+}
@@ -0,0 +1,98 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// SchemaVersion is the current layout of state.json. Bump it and add a
+// migration step whenever the layout changes, so machines provisioned
+// by older releases keep their history instead of having it discarded.
+const SchemaVersion = 2
+
+// File is the on-disk state envelope holding everything setup persists
+// between runs.
+type File struct {
+	Schema       int               `json:"schema"`
+	DurationsMS  map[string]int64  `json:"durations_ms,omitempty"`
+	Checksums    map[string]string `json:"checksums,omitempty"`
+	ManagedFiles map[string]string `json:"managed_files,omitempty"`
+	Channels     map[string]string `json:"channels,omitempty"`
+	Runs         []Run             `json:"runs,omitempty"`
+}
+
+// migrations upgrade a File by one schema step; the entry at key n
+// takes a schema-n File to schema n+1. Schema 1 — the pre-envelope
+// loose durations.json / checksums.json files — is handled separately
+// in load because there is no envelope to read it from.
+var migrations = map[int]func(File) File{}
+
+// load reads state.json, applying migrations as needed. Missing or
+// unreadable state yields an empty current-version File; persistence
+// is best-effort throughout this package.
+func load() File {
+	empty := File{Schema: SchemaVersion}
+	dir, err := Dir()
+	if err != nil {
+		return empty
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "state.json"))
+	if err != nil {
+		return migrateLoose(dir)
+	}
+	var f File
+	if json.Unmarshal(data, &f) != nil {
+		return empty
+	}
+	for f.Schema < SchemaVersion {
+		step, ok := migrations[f.Schema]
+		if !ok {
+			return empty
+		}
+		f = step(f)
+		f.Schema++
+	}
+	f.Schema = SchemaVersion
+	return f
+}
+
+// save writes the envelope back, always at the current schema.
+func save(f File) {
+	dir, err := Dir()
+	if err != nil {
+		return
+	}
+	f.Schema = SchemaVersion
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(dir, "state.json"), data, 0o644)
+}
+
+// migrateLoose imports the schema-1 layout: durations.json and
+// checksums.json as separate unversioned files. The loose files are
+// removed once their content is safely inside state.json.
+func migrateLoose(dir string) File {
+	f := File{Schema: SchemaVersion}
+	durations := filepath.Join(dir, "durations.json")
+	checksums := filepath.Join(dir, "checksums.json")
+	migrated := false
+	if data, err := os.ReadFile(durations); err == nil {
+		if json.Unmarshal(data, &f.DurationsMS) == nil {
+			migrated = true
+		}
+	}
+	if data, err := os.ReadFile(checksums); err == nil {
+		if json.Unmarshal(data, &f.Checksums) == nil {
+			migrated = true
+		}
+	}
+	if migrated {
+		save(f)
+		os.Remove(durations)
+		os.Remove(checksums)
+	}
+	return f
+}
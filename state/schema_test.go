@@ -0,0 +1,62 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func stateDir(t *testing.T) string {
+	t.Helper()
+	base := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", base)
+	return filepath.Join(base, "setup")
+}
+
+func TestMigratesLooseV1Files(t *testing.T) {
+	dir := stateDir(t)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "durations.json"), []byte(`{"Neovim": 1500}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "checksums.json"), []byte(`{"/usr/local/bin/nvim": "abc"}`), 0o644)
+
+	if got := LoadDurations()["Neovim"]; got != 1500*time.Millisecond {
+		t.Errorf("Neovim duration = %v, want 1.5s", got)
+	}
+	if got := LoadChecksums()["/usr/local/bin/nvim"]; got != "abc" {
+		t.Errorf("nvim checksum = %q, want abc", got)
+	}
+	// The history now lives in the envelope; the loose files are gone.
+	if _, err := os.Stat(filepath.Join(dir, "state.json")); err != nil {
+		t.Errorf("state.json not written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "durations.json")); !os.IsNotExist(err) {
+		t.Errorf("durations.json still present after migration")
+	}
+}
+
+func TestSavePreservesOtherSections(t *testing.T) {
+	stateDir(t)
+	SaveDurations(map[string]time.Duration{"Yazi": 2 * time.Second})
+	SaveChecksums(map[string]string{"/opt/yazi/yazi": "def"})
+
+	if got := LoadDurations()["Yazi"]; got != 2*time.Second {
+		t.Errorf("Yazi duration = %v after saving checksums, want 2s", got)
+	}
+	if f := load(); f.Schema != SchemaVersion {
+		t.Errorf("schema = %d, want %d", f.Schema, SchemaVersion)
+	}
+}
+
+func TestUnknownFutureSchemaStartsEmpty(t *testing.T) {
+	dir := stateDir(t)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "state.json"), []byte(`{"schema": 0, "durations_ms": {"x": 1}}`), 0o644)
+	if got := LoadDurations(); len(got) != 0 {
+		t.Errorf("durations = %v for unmigratable schema, want empty", got)
+	}
+}
@@ -0,0 +1,63 @@
+// Package state persists small pieces of machine-local data between
+// runs (e.g. historical step durations) under the XDG state directory.
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Dir returns setup's state directory ($XDG_STATE_HOME/setup, or
+// ~/.local/state/setup), creating it if needed.
+func Dir() (string, error) {
+	base := os.Getenv("XDG_STATE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".local", "state")
+	}
+	dir := filepath.Join(base, "setup")
+	return dir, os.MkdirAll(dir, 0o755)
+}
+
+// CacheDir returns setup's cache directory ($XDG_CACHE_HOME/setup, or
+// ~/.cache/setup), creating it if needed. Unlike the state directory
+// its contents are disposable: everything there can be re-downloaded.
+func CacheDir() (string, error) {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".cache")
+	}
+	dir := filepath.Join(base, "setup")
+	return dir, os.MkdirAll(dir, 0o755)
+}
+
+// LoadDurations returns the last recorded install duration per
+// component. Missing or unreadable state yields an empty map: the
+// estimates just start out unknown.
+func LoadDurations() map[string]time.Duration {
+	f := load()
+	durations := make(map[string]time.Duration, len(f.DurationsMS))
+	for name, v := range f.DurationsMS {
+		durations[name] = time.Duration(v) * time.Millisecond
+	}
+	return durations
+}
+
+// SaveDurations records install durations for future run estimates.
+// Failures are ignored; estimation is best-effort.
+func SaveDurations(durations map[string]time.Duration) {
+	f := load()
+	f.DurationsMS = make(map[string]int64, len(durations))
+	for name, d := range durations {
+		f.DurationsMS[name] = d.Milliseconds()
+	}
+	save(f)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ishida722/setup/logger"
+)
+
+// Snapshot is the exported machine state: which components are present
+// and at what version. Snapshots from different machines (or the same
+// machine at different times) can be compared with `setup state diff`.
+type Snapshot struct {
+	Host       string              `json:"host"`
+	Time       time.Time           `json:"time"`
+	Components []SnapshotComponent `json:"components"`
+}
+
+// SnapshotComponent is one component's state in a snapshot.
+type SnapshotComponent struct {
+	Name      string `json:"name"`
+	Installed bool   `json:"installed"`
+	Version   string `json:"version,omitempty"`
+}
+
+// runState exports and compares machine state snapshots:
+//
+//	setup state export [component flags] > machine.json
+//	setup state diff machineA.json machineB.json
+func runState(args []string) error {
+	if len(args) == 0 {
+		logger.Error("usage: setup state export|diff")
+		return fmt.Errorf("missing state subcommand")
+	}
+	switch args[0] {
+	case "export":
+		return stateExport(args[1:])
+	case "diff":
+		return stateDiff(args[1:])
+	default:
+		logger.Error("unknown state subcommand %q (use export or diff)", args[0])
+		return fmt.Errorf("unknown state subcommand %q", args[0])
+	}
+}
+
+// stateExport writes the current machine's snapshot to stdout. It
+// takes the same component flags as install/status, so the snapshot
+// covers the machine's intended profile.
+func stateExport(args []string) error {
+	cmds, err := selectComponents(flag.NewFlagSet("state export", flag.ExitOnError), args)
+	if err != nil {
+		return err
+	}
+	host, _ := os.Hostname()
+	snap := Snapshot{Host: host, Time: time.Now()}
+	for _, c := range cmds {
+		if !c.HasChecks() {
+			continue
+		}
+		sc := SnapshotComponent{Name: c.Name, Installed: c.Installed()}
+		if sc.Installed {
+			sc.Version = c.Version()
+		}
+		snap.Components = append(snap.Components, sc)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(snap)
+}
+
+// stateDiff compares two snapshots and reports every difference in
+// component presence and version. It exits non-zero when the machines
+// differ, so scripts can gate on it.
+func stateDiff(args []string) error {
+	if len(args) != 2 {
+		logger.Error("usage: setup state diff <a.json> <b.json>")
+		return fmt.Errorf("state diff needs two snapshot files")
+	}
+	a, err := readSnapshot(args[0])
+	if err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+	b, err := readSnapshot(args[1])
+	if err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+	labelA := fmt.Sprintf("%s (%s)", a.Host, a.Time.Format("2006-01-02 15:04"))
+	labelB := fmt.Sprintf("%s (%s)", b.Host, b.Time.Format("2006-01-02 15:04"))
+
+	inA := map[string]SnapshotComponent{}
+	for _, c := range a.Components {
+		inA[c.Name] = c
+	}
+	differences := 0
+	for _, cb := range b.Components {
+		ca, ok := inA[cb.Name]
+		if !ok {
+			fmt.Printf("%s: only covered by %s\n", cb.Name, labelB)
+			differences++
+			continue
+		}
+		delete(inA, cb.Name)
+		switch {
+		case ca.Installed != cb.Installed:
+			have, missing := labelA, labelB
+			if cb.Installed {
+				have, missing = labelB, labelA
+			}
+			fmt.Printf("%s: installed on %s but not on %s\n", cb.Name, have, missing)
+			differences++
+		case ca.Version != cb.Version:
+			fmt.Printf("%s: %s has %q, %s has %q\n", cb.Name, labelA, ca.Version, labelB, cb.Version)
+			differences++
+		}
+	}
+	for _, ca := range a.Components {
+		if _, ok := inA[ca.Name]; ok {
+			fmt.Printf("%s: only covered by %s\n", ca.Name, labelA)
+			differences++
+		}
+	}
+	if differences == 0 {
+		logger.Success("snapshots match (%d components)", len(b.Components))
+		return nil
+	}
+	return fmt.Errorf("%d differences", differences)
+}
+
+func readSnapshot(path string) (Snapshot, error) {
+	var s Snapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,107 @@
+// Package systemdunit renders and installs systemd unit files so
+// components (tailscale, node_exporter, custom agents) can register
+// services declaratively.
+package systemdunit
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/ishida722/setup/installer"
+)
+
+// Unit describes one unit file to install under /etc/systemd/system.
+type Unit struct {
+	// Name is the unit file name, e.g. "node_exporter.service".
+	Name string
+	// Content is the full unit file text, usually produced by Render.
+	Content string
+	// Enable starts the unit and enables it at boot after installing.
+	Enable bool
+	// User installs the unit as a systemd user unit under
+	// ~/.config/systemd/user and manages it with systemctl --user.
+	// Combine with Lingering so user daemons run without a session.
+	User bool
+}
+
+// Render executes a unit file template with the given data.
+func Render(tmpl string, data any) (string, error) {
+	t, err := template.New("unit").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("parse unit template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render unit template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Path returns where the unit file is installed.
+func (u Unit) Path() string {
+	if u.User {
+		return "$HOME/.config/systemd/user/" + u.Name
+	}
+	return "/etc/systemd/system/" + u.Name
+}
+
+func (u Unit) systemctl() string {
+	if u.User {
+		return "systemctl --user"
+	}
+	return "sudo systemctl"
+}
+
+// InstallCommand returns the idempotent install step for the unit: it
+// is skipped when the installed file already matches and (if Enable)
+// the unit is enabled; otherwise the file is written, daemon-reload is
+// run and the unit is enabled and started.
+func (u Unit) InstallCommand() installer.InstallCommand {
+	checks := []string{
+		fmt.Sprintf("cmp -s %s <(printf '%%s' %s)", u.Path(), Quote(u.Content)),
+	}
+	var commands []string
+	if u.User {
+		commands = append(commands,
+			"mkdir -p ~/.config/systemd/user",
+			fmt.Sprintf("printf '%%s' %s > %s", Quote(u.Content), u.Path()),
+		)
+	} else {
+		commands = append(commands,
+			fmt.Sprintf("printf '%%s' %s | sudo tee %s > /dev/null", Quote(u.Content), u.Path()),
+		)
+	}
+	commands = append(commands, u.systemctl()+" daemon-reload")
+	if u.Enable {
+		checks = append(checks, fmt.Sprintf("%s is-enabled --quiet %s", u.systemctl(), u.Name))
+		commands = append(commands, fmt.Sprintf("%s enable --now %s", u.systemctl(), u.Name))
+	}
+	return installer.InstallCommand{
+		Name:            "systemd unit " + u.Name,
+		CheckCommands:   checks,
+		InstallCommands: commands,
+	}
+}
+
+// Lingering enables loginctl lingering for the user so their user
+// units keep running without an active login session.
+func Lingering(user string) installer.InstallCommand {
+	if user == "" {
+		user = "$USER"
+	}
+	return installer.InstallCommand{
+		Name:          "loginctl lingering for " + user,
+		CheckCommands: []string{fmt.Sprintf(`test "$(loginctl show-user %s --property=Linger --value)" = yes`, user)},
+		InstallCommands: []string{
+			"sudo loginctl enable-linger " + user,
+		},
+	}
+}
+
+// Quote wraps s in single quotes for safe interpolation into a shell
+// command.
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
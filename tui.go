@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ishida722/setup/installer"
+	"github.com/ishida722/setup/logger"
+)
+
+// tuiTailLines is how many lines of the running step's output the
+// dashboard pane shows.
+const tuiTailLines = 12
+
+// runTUI executes the components with a full-screen dashboard instead
+// of the scrolling log: a live component list, a pane streaming the
+// current step's output, and overall progress.
+func runTUI(cmds []installer.InstallCommand) ([]installer.Result, error) {
+	if !logger.IsTerminal(os.Stdout) {
+		logger.Warn("stdout is not a terminal; running without the TUI")
+		return installer.RunAllResults(cmds)
+	}
+	d := &dashboard{cmds: cmds, status: make([]string, len(cmds))}
+	for i := range d.status {
+		d.status[i] = "pending"
+	}
+	fmt.Print("\033[?1049h\033[?25l") // alternate screen, hide cursor
+	defer fmt.Print("\033[?1049l\033[?25h")
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(200 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				d.render()
+			}
+		}
+	}()
+
+	var firstErr error
+	results := make([]installer.Result, 0, len(cmds))
+	for i, c := range cmds {
+		if c.Installed() {
+			d.set(i, "skipped")
+			results = append(results, installer.Result{Name: c.Name, Action: "skipped", Version: c.Version()})
+			continue
+		}
+		d.set(i, "running")
+		start := time.Now()
+		err := c.InstallTo(d.tailWriter(), d.tailWriter())
+		elapsed := time.Since(start).Round(100 * time.Millisecond)
+		if err != nil {
+			d.set(i, "failed")
+			if firstErr == nil {
+				firstErr = err
+			}
+			results = append(results, installer.Result{Name: c.Name, Action: "failed", Duration: elapsed, Err: err})
+			continue
+		}
+		d.set(i, "installed")
+		results = append(results, installer.Result{Name: c.Name, Action: "installed", Version: c.Version(), Duration: elapsed})
+	}
+	close(stop)
+	d.render()
+	time.Sleep(300 * time.Millisecond)
+	return results, firstErr
+}
+
+type dashboard struct {
+	mu     sync.Mutex
+	cmds   []installer.InstallCommand
+	status []string
+	tail   []string
+}
+
+func (d *dashboard) set(i int, status string) {
+	d.mu.Lock()
+	d.status[i] = status
+	if status == "running" {
+		d.tail = nil
+	}
+	d.mu.Unlock()
+	d.render()
+}
+
+// tailWriter feeds the output pane.
+func (d *dashboard) tailWriter() *tailBuffer { return &tailBuffer{d: d} }
+
+type tailBuffer struct {
+	d   *dashboard
+	buf strings.Builder
+}
+
+func (t *tailBuffer) Write(b []byte) (int, error) {
+	t.buf.Write(b)
+	for {
+		s := t.buf.String()
+		idx := strings.IndexByte(s, '\n')
+		if idx < 0 {
+			break
+		}
+		t.d.appendTail(s[:idx])
+		t.buf.Reset()
+		t.buf.WriteString(s[idx+1:])
+	}
+	return len(b), nil
+}
+
+func (d *dashboard) appendTail(line string) {
+	d.mu.Lock()
+	d.tail = append(d.tail, line)
+	if len(d.tail) > tuiTailLines {
+		d.tail = d.tail[len(d.tail)-tuiTailLines:]
+	}
+	d.mu.Unlock()
+}
+
+var statusStyle = map[string]string{
+	"pending":   "\033[90m·\033[0m",
+	"running":   "\033[33m>\033[0m",
+	"installed": "\033[32m✔\033[0m",
+	"skipped":   "\033[34m-\033[0m",
+	"failed":    "\033[31m✘\033[0m",
+}
+
+func (d *dashboard) render() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var b strings.Builder
+	b.WriteString("\033[H\033[2J") // home + clear
+	done := 0
+	for _, s := range d.status {
+		if s != "pending" && s != "running" {
+			done++
+		}
+	}
+	fmt.Fprintf(&b, "setup — %d/%d components\n\n", done, len(d.status))
+	for i, c := range d.cmds {
+		fmt.Fprintf(&b, " %s %s\n", statusStyle[d.status[i]], c.Name)
+	}
+	b.WriteString("\n────────────────────────────────────────\n")
+	for _, line := range d.tail {
+		fmt.Fprintf(&b, " %s\n", line)
+	}
+	fmt.Print(b.String())
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ishida722/setup/logger"
+	"github.com/ishida722/setup/shellwords"
+)
+
+// workspaceFile maps roles to the machines that play them and the
+// install flags they get, so one command reconciles a small fleet:
+//
+//	roles:
+//	  laptop:
+//	    local: true
+//	    args: -tlp -audio -clipboard
+//	  buildbox:
+//	    hosts: [dev@build1, dev@build2]
+//	    args: -k8s-tools -databases -aws-region ${region}
+//	    vars:
+//	      region: ap-northeast-1
+type workspaceFile struct {
+	Roles map[string]workspaceRole `yaml:"roles"`
+}
+
+// workspaceRole is one role: where it runs and with which flags. Vars
+// are substituted into args as ${name}.
+type workspaceRole struct {
+	Hosts []string          `yaml:"hosts"`
+	Local bool              `yaml:"local"`
+	Args  string            `yaml:"args"`
+	Vars  map[string]string `yaml:"vars"`
+}
+
+// runWorkspace handles `setup workspace apply`: every remote host gets
+// provisioned through the remote mode, a local role runs in-process.
+func runWorkspace(args []string) error {
+	if len(args) == 0 || args[0] != "apply" {
+		logger.Error("usage: setup workspace apply [-file workspace.yaml] [-role name]")
+		return fmt.Errorf("unknown workspace command")
+	}
+	fs := flag.NewFlagSet("workspace apply", flag.ExitOnError)
+	file := fs.String("file", "workspace.yaml", "workspace definition file")
+	role := fs.String("role", "", "apply only this role (default: every role)")
+	concurrency := fs.Int("concurrency", 4, "maximum remote hosts provisioned at once")
+	fs.Parse(args[1:])
+
+	ws, err := loadWorkspace(*file)
+	if err != nil {
+		logger.Error("%s: %v", *file, err)
+		return err
+	}
+	names := make([]string, 0, len(ws.Roles))
+	for name := range ws.Roles {
+		if *role == "" || name == *role {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		err := fmt.Errorf("role %q not in %s", *role, *file)
+		logger.Error("%v", err)
+		return err
+	}
+	sort.Strings(names)
+
+	failed := 0
+	for _, name := range names {
+		if err := applyWorkspaceRole(name, ws.Roles[name], *concurrency); err != nil {
+			logger.Error("role %s: %v", name, err)
+			failed++
+		} else {
+			logger.Success("role %s: reconciled", name)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d/%d roles failed", failed, len(names))
+	}
+	return nil
+}
+
+// loadWorkspace parses the workspace file strictly, like the project
+// manifest.
+func loadWorkspace(path string) (workspaceFile, error) {
+	var ws workspaceFile
+	f, err := os.Open(path)
+	if err != nil {
+		return ws, err
+	}
+	defer f.Close()
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&ws); err != nil {
+		return ws, err
+	}
+	return ws, nil
+}
+
+// applyWorkspaceRole provisions every machine playing the role: remote
+// hosts concurrently via SSH, the local machine in-process.
+func applyWorkspaceRole(name string, role workspaceRole, concurrency int) error {
+	args := os.Expand(role.Args, func(key string) string { return role.Vars[key] })
+	logger.Info("role %s: %d hosts, args: %s", name, len(role.Hosts)+boolToInt(role.Local), args)
+
+	var localErr error
+	if role.Local {
+		split, err := shellwords.Split(args)
+		if err != nil {
+			return fmt.Errorf("args: %w", err)
+		}
+		localErr = runInstall(false, append(split, "-yes"))
+	}
+
+	var remoteFailed int
+	if len(role.Hosts) > 0 {
+		self, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("cannot locate setup binary: %w", err)
+		}
+		sem := make(chan struct{}, concurrency)
+		errs := make([]error, len(role.Hosts))
+		var wg sync.WaitGroup
+		for i, host := range role.Hosts {
+			i, host := i, host
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				errs[i] = provisionHost(host, self, args)
+			}()
+		}
+		wg.Wait()
+		for i, err := range errs {
+			if err != nil {
+				logger.Error("%s: failed: %v", role.Hosts[i], err)
+				remoteFailed++
+			}
+		}
+	}
+	var problems []string
+	if localErr != nil {
+		problems = append(problems, fmt.Sprintf("local install failed: %v", localErr))
+	}
+	if remoteFailed > 0 {
+		problems = append(problems, fmt.Sprintf("%d/%d hosts failed", remoteFailed, len(role.Hosts)))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}